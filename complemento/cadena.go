@@ -0,0 +1,150 @@
+package complemento
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrComplementoSinRegla es el error de la cadena original de un comprobante
+// con un complemento que no se sabe recorrer. Recorrerlo en el orden del
+// archivo daría una cadena distinta a la del SAT
+var ErrComplementoSinRegla = errors.New("complemento sin regla")
+
+// Nodo es un elemento del XML con sus atributos e hijos en el orden del archivo,
+// sirve para recorrer el comprobante completo como lo hacen las XSLT del SAT
+type Nodo struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodos   []Nodo     `xml:",any"`
+}
+
+// ParseNodo lee el XML completo del comprobante
+func ParseNodo(content []byte) (Nodo, error) {
+	var n Nodo
+	err := xml.Unmarshal(content, &n)
+	return n, err
+}
+
+// Attr regresa el valor del atributo y si viene en el nodo
+func (n Nodo) Attr(name string) (string, bool) {
+	for _, attr := range n.Attrs {
+		if attr.Name.Local == name && attr.Name.Space != "xmlns" {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// buscar regresa los nodos de la ruta relativa en el orden del archivo, por
+// ejemplo Impuestos/Traslados/Traslado
+func (n Nodo) buscar(ruta []string) []Nodo {
+	found := make([]Nodo, 0)
+	for _, hijo := range n.Nodos {
+		if hijo.XMLName.Local != ruta[0] {
+			continue
+		}
+		if len(ruta) == 1 {
+			found = append(found, hijo)
+			continue
+		}
+		found = append(found, hijo.buscar(ruta[1:])...)
+	}
+	return found
+}
+
+// CadenaOriginal arma la cadena original del comprobante 3.3 o 4.0 con las
+// reglas de las XSLT del SAT, incluidos los complementos. El timbre y la
+// addenda no forman parte de la cadena
+func CadenaOriginal(content []byte) (string, error) {
+	n, err := ParseNodo(content)
+	if err != nil {
+		return "", err
+	}
+	return n.CadenaOriginal()
+}
+
+// CadenaOriginal arma la cadena original del comprobante ya leído
+func (n Nodo) CadenaOriginal() (string, error) {
+	if n.XMLName.Local != "Comprobante" {
+		return "", fmt.Errorf("el XML no es un comprobante: %s", n.XMLName.Local)
+	}
+
+	version, _ := n.Attr("Version")
+	regla, ok := comprobanteReglas[version]
+	if !ok {
+		return "", fmt.Errorf("cadena original de la versión %q no soportada", version)
+	}
+
+	values := make([]string, 0)
+	if err := n.aplicar(regla, &values); err != nil {
+		return "", err
+	}
+
+	return cadena(values), nil
+}
+
+// CadenaOriginal arma la cadena original del TimbreFiscalDigital 1.1
+func (t TimbreFiscalDigital) CadenaOriginal() string {
+	values := []string{t.Version, t.UUID, t.FechaTimbrado, t.RfcProvCertif}
+	if t.Leyenda != "" {
+		values = append(values, t.Leyenda)
+	}
+	values = append(values, t.SelloCFD, t.NoCertificadoSAT)
+
+	for i, value := range values {
+		values[i] = normalizeSpace(value)
+	}
+	return cadena(values)
+}
+
+func cadena(values []string) string {
+	return "||" + strings.Join(values, "|") + "||"
+}
+
+// aplicar recorre los pasos de la regla, igual que los templates de la XSLT
+func (n Nodo) aplicar(regla string, values *[]string) error {
+	for _, p := range cadenaReglas[regla] {
+		switch {
+		case p.attr != "":
+			value, ok := n.Attr(p.attr)
+			//Los atributos requeridos se escriben aunque no vengan
+			if ok || !p.opcional {
+				*values = append(*values, normalizeSpace(value))
+			}
+		case p.ruta != "":
+			for _, hijo := range n.buscar(strings.Split(p.ruta, "/")) {
+				if err := hijo.aplicar(p.regla, values); err != nil {
+					return err
+				}
+			}
+		case p.complementos:
+			for _, hijo := range n.Nodos {
+				if err := hijo.aplicarComplemento(values); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// aplicarComplemento usa la regla del complemento según su espacio de nombres,
+// un complemento sin regla es un error
+func (n Nodo) aplicarComplemento(values *[]string) error {
+	if n.XMLName.Space == namespaceTFD {
+		return nil
+	}
+	regla, ok := complementoReglas[n.XMLName.Space+" "+n.XMLName.Local]
+	if !ok {
+		return fmt.Errorf("%w: %s (%s)", ErrComplementoSinRegla, n.XMLName.Local, n.XMLName.Space)
+	}
+	return n.aplicar(regla, values)
+}
+
+// normalizeSpace hace lo mismo que normalize-space de XSLT, quita los espacios
+// de los extremos y deja uno solo entre palabras
+func normalizeSpace(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
@@ -0,0 +1,526 @@
+package complemento
+
+// Espacios de nombres que se usan al armar la cadena original
+const (
+	namespaceTFD      = "http://www.sat.gob.mx/TimbreFiscalDigital"
+	namespacePagos10  = "http://www.sat.gob.mx/Pagos"
+	namespacePagos20  = "http://www.sat.gob.mx/Pagos20"
+	namespaceImpLocal = "http://www.sat.gob.mx/implocal"
+	namespaceNomina12 = "http://www.sat.gob.mx/nomina12"
+	namespaceLeyendas = "http://www.sat.gob.mx/leyendasFiscales"
+	namespaceXSI      = "http://www.w3.org/2001/XMLSchema-instance"
+)
+
+// paso es una instrucción de la XSLT del SAT: escribir un atributo, aplicar
+// una regla a los nodos de una ruta o aplicar las reglas de los complementos
+type paso struct {
+	attr     string
+	opcional bool
+
+	ruta  string
+	regla string
+
+	complementos bool
+}
+
+func requerido(attr string) paso {
+	return paso{attr: attr}
+}
+
+func opcional(attr string) paso {
+	return paso{attr: attr, opcional: true}
+}
+
+func hijos(ruta, regla string) paso {
+	return paso{ruta: ruta, regla: regla}
+}
+
+// Regla del comprobante según su versión
+var comprobanteReglas = map[string]string{
+	"3.3": "Comprobante33",
+	"4.0": "Comprobante40",
+}
+
+// Regla de cada complemento por espacio de nombres y nodo
+var complementoReglas = map[string]string{
+	namespacePagos10 + " Pagos":             "Pagos10",
+	namespacePagos20 + " Pagos":             "Pagos20",
+	namespaceImpLocal + " ImpuestosLocales": "ImpuestosLocales",
+	namespaceNomina12 + " Nomina":           "Nomina12",
+	namespaceLeyendas + " LeyendasFiscales": "LeyendasFiscales",
+}
+
+// cadenaReglas siguen el orden de cadenaoriginal_3_3.xslt, cadenaoriginal_4_0.xslt
+// y las XSLT de los complementos que publica el SAT
+var cadenaReglas = map[string][]paso{
+	"Comprobante40": {
+		requerido("Version"),
+		opcional("Serie"),
+		opcional("Folio"),
+		requerido("Fecha"),
+		opcional("FormaPago"),
+		requerido("NoCertificado"),
+		opcional("CondicionesDePago"),
+		requerido("SubTotal"),
+		opcional("Descuento"),
+		requerido("Moneda"),
+		opcional("TipoCambio"),
+		requerido("Total"),
+		requerido("TipoDeComprobante"),
+		requerido("Exportacion"),
+		opcional("MetodoPago"),
+		requerido("LugarExpedicion"),
+		opcional("Confirmacion"),
+		hijos("InformacionGlobal", "InformacionGlobal"),
+		hijos("CfdiRelacionados", "CfdiRelacionados"),
+		hijos("Emisor", "Emisor40"),
+		hijos("Receptor", "Receptor40"),
+		hijos("Conceptos/Concepto", "Concepto40"),
+		hijos("Impuestos", "Impuestos40"),
+		hijos("Complemento", "Complemento"),
+	},
+	"Comprobante33": {
+		requerido("Version"),
+		opcional("Serie"),
+		opcional("Folio"),
+		requerido("Fecha"),
+		opcional("FormaPago"),
+		requerido("NoCertificado"),
+		opcional("CondicionesDePago"),
+		requerido("SubTotal"),
+		opcional("Descuento"),
+		requerido("Moneda"),
+		opcional("TipoCambio"),
+		requerido("Total"),
+		requerido("TipoDeComprobante"),
+		opcional("MetodoPago"),
+		requerido("LugarExpedicion"),
+		opcional("Confirmacion"),
+		hijos("CfdiRelacionados", "CfdiRelacionados"),
+		hijos("Emisor", "Emisor33"),
+		hijos("Receptor", "Receptor33"),
+		hijos("Conceptos/Concepto", "Concepto33"),
+		hijos("Impuestos", "Impuestos33"),
+		hijos("Complemento", "Complemento"),
+	},
+	"InformacionGlobal": {
+		requerido("Periodicidad"),
+		requerido("Meses"),
+		requerido("Año"),
+	},
+	"CfdiRelacionados": {
+		requerido("TipoRelacion"),
+		hijos("CfdiRelacionado", "CfdiRelacionado"),
+	},
+	"CfdiRelacionado": {
+		requerido("UUID"),
+	},
+	"Emisor40": {
+		requerido("Rfc"),
+		requerido("Nombre"),
+		requerido("RegimenFiscal"),
+		opcional("FacAtrAdquirente"),
+	},
+	"Emisor33": {
+		requerido("Rfc"),
+		opcional("Nombre"),
+		requerido("RegimenFiscal"),
+	},
+	"Receptor40": {
+		requerido("Rfc"),
+		requerido("Nombre"),
+		requerido("DomicilioFiscalReceptor"),
+		opcional("ResidenciaFiscal"),
+		opcional("NumRegIdTrib"),
+		requerido("RegimenFiscalReceptor"),
+		requerido("UsoCFDI"),
+	},
+	"Receptor33": {
+		requerido("Rfc"),
+		opcional("Nombre"),
+		opcional("ResidenciaFiscal"),
+		opcional("NumRegIdTrib"),
+		requerido("UsoCFDI"),
+	},
+	"Concepto40": {
+		requerido("ClaveProdServ"),
+		opcional("NoIdentificacion"),
+		requerido("Cantidad"),
+		requerido("ClaveUnidad"),
+		opcional("Unidad"),
+		requerido("Descripcion"),
+		requerido("ValorUnitario"),
+		requerido("Importe"),
+		opcional("Descuento"),
+		requerido("ObjetoImp"),
+		hijos("Impuestos/Traslados/Traslado", "TrasladoConcepto"),
+		hijos("Impuestos/Retenciones/Retencion", "RetencionConcepto"),
+		hijos("ACuentaTerceros", "ACuentaTerceros"),
+		hijos("InformacionAduanera", "InformacionAduanera"),
+		hijos("CuentaPredial", "CuentaPredial"),
+		hijos("ComplementoConcepto", "Complemento"),
+		hijos("Parte", "Parte"),
+	},
+	"Concepto33": {
+		requerido("ClaveProdServ"),
+		opcional("NoIdentificacion"),
+		requerido("Cantidad"),
+		requerido("ClaveUnidad"),
+		opcional("Unidad"),
+		requerido("Descripcion"),
+		requerido("ValorUnitario"),
+		requerido("Importe"),
+		opcional("Descuento"),
+		hijos("Impuestos/Traslados/Traslado", "TrasladoConcepto"),
+		hijos("Impuestos/Retenciones/Retencion", "RetencionConcepto"),
+		hijos("InformacionAduanera", "InformacionAduanera"),
+		hijos("CuentaPredial", "CuentaPredial"),
+		hijos("ComplementoConcepto", "Complemento"),
+		hijos("Parte", "Parte"),
+	},
+	"TrasladoConcepto": {
+		requerido("Base"),
+		requerido("Impuesto"),
+		requerido("TipoFactor"),
+		opcional("TasaOCuota"),
+		opcional("Importe"),
+	},
+	"RetencionConcepto": {
+		requerido("Base"),
+		requerido("Impuesto"),
+		requerido("TipoFactor"),
+		requerido("TasaOCuota"),
+		requerido("Importe"),
+	},
+	"ACuentaTerceros": {
+		requerido("RfcACuentaTerceros"),
+		requerido("NombreACuentaTerceros"),
+		requerido("RegimenFiscalACuentaTerceros"),
+		requerido("DomicilioFiscalACuentaTerceros"),
+	},
+	"InformacionAduanera": {
+		requerido("NumeroPedimento"),
+	},
+	"CuentaPredial": {
+		requerido("Numero"),
+	},
+	"Parte": {
+		requerido("ClaveProdServ"),
+		opcional("NoIdentificacion"),
+		requerido("Cantidad"),
+		opcional("Unidad"),
+		requerido("Descripcion"),
+		opcional("ValorUnitario"),
+		opcional("Importe"),
+		hijos("InformacionAduanera", "InformacionAduanera"),
+	},
+	"Impuestos40": {
+		hijos("Retenciones/Retencion", "Retencion"),
+		opcional("TotalImpuestosRetenidos"),
+		hijos("Traslados/Traslado", "TrasladoConcepto"),
+		opcional("TotalImpuestosTrasladados"),
+	},
+	"Impuestos33": {
+		hijos("Retenciones/Retencion", "Retencion"),
+		opcional("TotalImpuestosRetenidos"),
+		hijos("Traslados/Traslado", "Traslado33"),
+		opcional("TotalImpuestosTrasladados"),
+	},
+	"Retencion": {
+		requerido("Impuesto"),
+		requerido("Importe"),
+	},
+	"Traslado33": {
+		requerido("Impuesto"),
+		requerido("TipoFactor"),
+		requerido("TasaOCuota"),
+		requerido("Importe"),
+	},
+	"Complemento": {
+		{complementos: true},
+	},
+
+	// pagos10.xslt
+	"Pagos10": {
+		requerido("Version"),
+		hijos("Pago", "Pago10"),
+	},
+	"Pago10": {
+		requerido("FechaPago"),
+		requerido("FormaDePagoP"),
+		requerido("MonedaP"),
+		opcional("TipoCambioP"),
+		requerido("Monto"),
+		opcional("NumOperacion"),
+		opcional("RfcEmisorCtaOrd"),
+		opcional("NomBancoOrdExt"),
+		opcional("CtaOrdenante"),
+		opcional("RfcEmisorCtaBen"),
+		opcional("CtaBeneficiario"),
+		opcional("TipoCadPago"),
+		opcional("CertPago"),
+		opcional("CadPago"),
+		opcional("SelloPago"),
+		hijos("DoctoRelacionado", "DoctoRelacionado10"),
+		hijos("Impuestos", "Impuestos10"),
+	},
+	"DoctoRelacionado10": {
+		requerido("IdDocumento"),
+		opcional("Serie"),
+		opcional("Folio"),
+		requerido("MonedaDR"),
+		opcional("TipoCambioDR"),
+		requerido("MetodoDePagoDR"),
+		opcional("NumParcialidad"),
+		opcional("ImpSaldoAnt"),
+		opcional("ImpPagado"),
+		opcional("ImpSaldoInsoluto"),
+	},
+	"Impuestos10": {
+		opcional("TotalImpuestosRetenidos"),
+		opcional("TotalImpuestosTrasladados"),
+		hijos("Retenciones/Retencion", "Retencion"),
+		hijos("Traslados/Traslado", "Traslado33"),
+	},
+
+	// pagos20.xslt
+	"Pagos20": {
+		requerido("Version"),
+		hijos("Totales", "Totales20"),
+		hijos("Pago", "Pago20"),
+	},
+	"Totales20": {
+		opcional("TotalRetencionesIVA"),
+		opcional("TotalRetencionesISR"),
+		opcional("TotalRetencionesIEPS"),
+		opcional("TotalTrasladosBaseIVA16"),
+		opcional("TotalTrasladosImpuestoIVA16"),
+		opcional("TotalTrasladosBaseIVA8"),
+		opcional("TotalTrasladosImpuestoIVA8"),
+		opcional("TotalTrasladosBaseIVA0"),
+		opcional("TotalTrasladosImpuestoIVA0"),
+		opcional("TotalTrasladosBaseIVAExento"),
+		requerido("MontoTotalPagos"),
+	},
+	"Pago20": {
+		requerido("FechaPago"),
+		requerido("FormaDePagoP"),
+		requerido("MonedaP"),
+		opcional("TipoCambioP"),
+		requerido("Monto"),
+		opcional("NumOperacion"),
+		opcional("RfcEmisorCtaOrd"),
+		opcional("NomBancoOrdExt"),
+		opcional("CtaOrdenante"),
+		opcional("RfcEmisorCtaBen"),
+		opcional("CtaBeneficiario"),
+		opcional("TipoCadPago"),
+		opcional("CertPago"),
+		opcional("CadPago"),
+		opcional("SelloPago"),
+		hijos("DoctoRelacionado", "DoctoRelacionado20"),
+		hijos("ImpuestosP", "ImpuestosP20"),
+	},
+	"DoctoRelacionado20": {
+		requerido("IdDocumento"),
+		opcional("Serie"),
+		opcional("Folio"),
+		requerido("MonedaDR"),
+		opcional("EquivalenciaDR"),
+		requerido("NumParcialidad"),
+		requerido("ImpSaldoAnt"),
+		requerido("ImpPagado"),
+		requerido("ImpSaldoInsoluto"),
+		requerido("ObjetoImpDR"),
+		hijos("ImpuestosDR/RetencionesDR/RetencionDR", "RetencionDR20"),
+		hijos("ImpuestosDR/TrasladosDR/TrasladoDR", "TrasladoDR20"),
+	},
+	"RetencionDR20": {
+		requerido("BaseDR"),
+		requerido("ImpuestoDR"),
+		requerido("TipoFactorDR"),
+		requerido("TasaOCuotaDR"),
+		requerido("ImporteDR"),
+	},
+	"TrasladoDR20": {
+		requerido("BaseDR"),
+		requerido("ImpuestoDR"),
+		requerido("TipoFactorDR"),
+		opcional("TasaOCuotaDR"),
+		opcional("ImporteDR"),
+	},
+	"ImpuestosP20": {
+		hijos("RetencionesP/RetencionP", "RetencionP20"),
+		hijos("TrasladosP/TrasladoP", "TrasladoP20"),
+	},
+	"RetencionP20": {
+		requerido("ImpuestoP"),
+		requerido("ImporteP"),
+	},
+	"TrasladoP20": {
+		requerido("BaseP"),
+		requerido("ImpuestoP"),
+		requerido("TipoFactorP"),
+		opcional("TasaOCuotaP"),
+		opcional("ImporteP"),
+	},
+
+	// implocal.xslt
+	"ImpuestosLocales": {
+		requerido("version"),
+		requerido("TotaldeRetenciones"),
+		requerido("TotaldeTraslados"),
+		hijos("RetencionesLocales", "RetencionesLocales"),
+		hijos("TrasladosLocales", "TrasladosLocales"),
+	},
+	"RetencionesLocales": {
+		requerido("ImpLocRetenido"),
+		requerido("TasadeRetencion"),
+		requerido("Importe"),
+	},
+	"TrasladosLocales": {
+		requerido("ImpLocTrasladado"),
+		requerido("TasadeTraslado"),
+		requerido("Importe"),
+	},
+
+	// nomina12.xslt
+	"Nomina12": {
+		requerido("Version"),
+		requerido("TipoNomina"),
+		requerido("FechaPago"),
+		requerido("FechaInicialPago"),
+		requerido("FechaFinalPago"),
+		requerido("NumDiasPagados"),
+		opcional("TotalPercepciones"),
+		opcional("TotalDeducciones"),
+		opcional("TotalOtrosPagos"),
+		hijos("Emisor", "EmisorNomina12"),
+		hijos("Receptor", "ReceptorNomina12"),
+		hijos("Percepciones", "Percepciones12"),
+		hijos("Deducciones", "Deducciones12"),
+		hijos("OtrosPagos/OtroPago", "OtroPago12"),
+		hijos("Incapacidades/Incapacidad", "Incapacidad12"),
+	},
+	"EmisorNomina12": {
+		opcional("Curp"),
+		opcional("RegistroPatronal"),
+		opcional("RfcPatronOrigen"),
+		hijos("EntidadSNCF", "EntidadSNCF12"),
+	},
+	"EntidadSNCF12": {
+		requerido("OrigenRecurso"),
+		opcional("MontoRecursoPropio"),
+	},
+	"ReceptorNomina12": {
+		requerido("Curp"),
+		opcional("NumSeguridadSocial"),
+		opcional("FechaInicioRelLaboral"),
+		opcional("Antigüedad"),
+		requerido("TipoContrato"),
+		opcional("Sindicalizado"),
+		opcional("TipoJornada"),
+		requerido("TipoRegimen"),
+		requerido("NumEmpleado"),
+		opcional("Departamento"),
+		opcional("Puesto"),
+		opcional("RiesgoPuesto"),
+		requerido("PeriodicidadPago"),
+		opcional("Banco"),
+		opcional("CuentaBancaria"),
+		opcional("SalarioBaseCotApor"),
+		opcional("SalarioDiarioIntegrado"),
+		requerido("ClaveEntFed"),
+		hijos("SubContratacion", "SubContratacion12"),
+	},
+	"SubContratacion12": {
+		requerido("RfcLabora"),
+		requerido("PorcentajeTiempo"),
+	},
+	"Percepciones12": {
+		opcional("TotalSueldos"),
+		opcional("TotalSeparacionIndemnizacion"),
+		opcional("TotalJubilacionPensionRetiro"),
+		requerido("TotalGravado"),
+		requerido("TotalExento"),
+		hijos("Percepcion", "Percepcion12"),
+		hijos("JubilacionPensionRetiro", "JubilacionPensionRetiro12"),
+		hijos("SeparacionIndemnizacion", "SeparacionIndemnizacion12"),
+	},
+	"Percepcion12": {
+		requerido("TipoPercepcion"),
+		requerido("Clave"),
+		requerido("Concepto"),
+		requerido("ImporteGravado"),
+		requerido("ImporteExento"),
+		hijos("AccionesOTitulos", "AccionesOTitulos12"),
+		hijos("HorasExtra", "HorasExtra12"),
+	},
+	"AccionesOTitulos12": {
+		requerido("ValorMercado"),
+		requerido("PrecioAlOtorgarse"),
+	},
+	"HorasExtra12": {
+		requerido("Dias"),
+		requerido("TipoHoras"),
+		requerido("HorasExtra"),
+		requerido("ImportePagado"),
+	},
+	"JubilacionPensionRetiro12": {
+		opcional("TotalUnaExhibicion"),
+		opcional("TotalParcialidad"),
+		opcional("MontoDiario"),
+		requerido("IngresoAcumulable"),
+		requerido("IngresoNoAcumulable"),
+	},
+	"SeparacionIndemnizacion12": {
+		requerido("TotalPagado"),
+		requerido("NumAñosServicio"),
+		requerido("UltimoSueldoMensOrd"),
+		requerido("IngresoAcumulable"),
+		requerido("IngresoNoAcumulable"),
+	},
+	"Deducciones12": {
+		opcional("TotalOtrasDeducciones"),
+		opcional("TotalImpuestosRetenidos"),
+		hijos("Deduccion", "Deduccion12"),
+	},
+	"Deduccion12": {
+		requerido("TipoDeduccion"),
+		requerido("Clave"),
+		requerido("Concepto"),
+		requerido("Importe"),
+	},
+	"OtroPago12": {
+		requerido("TipoOtroPago"),
+		requerido("Clave"),
+		requerido("Concepto"),
+		requerido("Importe"),
+		hijos("SubsidioAlEmpleo", "SubsidioAlEmpleo12"),
+		hijos("CompensacionSaldosAFavor", "CompensacionSaldosAFavor12"),
+	},
+	"SubsidioAlEmpleo12": {
+		requerido("SubsidioCausado"),
+	},
+	"CompensacionSaldosAFavor12": {
+		requerido("SaldoAFavor"),
+		requerido("Año"),
+		requerido("RemanenteSalFav"),
+	},
+	"Incapacidad12": {
+		requerido("DiasIncapacidad"),
+		requerido("TipoIncapacidad"),
+		opcional("ImporteMonetario"),
+	},
+
+	// leyendasFisc.xslt
+	"LeyendasFiscales": {
+		requerido("version"),
+		hijos("Leyenda", "Leyenda"),
+	},
+	"Leyenda": {
+		opcional("disposicionFiscal"),
+		opcional("norma"),
+		requerido("textoLeyenda"),
+	},
+}
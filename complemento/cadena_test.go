@@ -0,0 +1,95 @@
+package complemento
+
+import (
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Las cadenas esperadas de testdata se armaron a mano con las XSLT del SAT,
+// los XML traen atributos fuera del orden del esquema y espacios de más a
+// propósito
+func TestCadenaOriginal(t *testing.T) {
+	tests := []string{
+		"cfdi40_ingreso",
+		"cfdi33_pagos10",
+		"cfdi40_pagos20",
+		"cfdi33_implocal",
+		"cfdi40_nomina12",
+	}
+
+	for _, name := range tests {
+		t.Run(name, func(t *testing.T) {
+			content := readTestdata(t, name+".xml")
+			want := readTestdata(t, name+".txt")
+
+			got, err := CadenaOriginal(content)
+			if err != nil {
+				t.Fatalf("CadenaOriginal: %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("cadena distinta\n got: %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestCadenaOriginalComplementoSinRegla(t *testing.T) {
+	content := readTestdata(t, "cfdi40_comercio_exterior.xml")
+
+	_, err := CadenaOriginal(content)
+	if !errors.Is(err, ErrComplementoSinRegla) {
+		t.Fatalf("error = %v, se esperaba ErrComplementoSinRegla", err)
+	}
+}
+
+func TestCadenaOriginalVersionNoSoportada(t *testing.T) {
+	content := `<cfdi:Comprobante xmlns:cfdi="http://www.sat.gob.mx/cfd/3" version="3.2" fecha="2016-01-01T10:00:00" total="100.00"/>`
+
+	if _, err := CadenaOriginal([]byte(content)); err == nil {
+		t.Fatal("se esperaba error para la versión 3.2")
+	}
+}
+
+func TestTimbreCadenaOriginal(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{
+			file: "cfdi40_ingreso.xml",
+			want: "||1.1|6E3A4B2C-1D5F-4A7B-9C8D-0E1F2A3B4C5D|2023-05-10T12:31:05|SPR190613I52|c2VsbG8=|30001000000500003456||",
+		},
+		{
+			//Con Leyenda, va entre RfcProvCertif y SelloCFD
+			file: "cfdi33_pagos10.xml",
+			want: "||1.1|1F2E3D4C-5B6A-4978-8A9B-0C1D2E3F4A5B|2021-03-01T10:01:00|SPR190613I52|Comprobante emitido en términos de la RMF|c2VsbG8=|30001000000400002495||",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			var c CFDI
+			if err := xml.Unmarshal(readTestdata(t, tt.file), &c); err != nil {
+				t.Fatal(err)
+			}
+
+			got := c.Complemento.TimbreFiscalDigital.CadenaOriginal()
+			if got != tt.want {
+				t.Errorf("cadena distinta\n got: %s\nwant: %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []byte(strings.TrimSuffix(string(content), "\n"))
+}
@@ -2,15 +2,20 @@ package complemento
 
 import (
 	"encoding/xml"
+	"strconv"
 )
 
 type CFDI struct {
 	Complemento       ComplementoCFDI `xml:"Complemento"`
+	Conceptos         []Concepto      `xml:"Conceptos>Concepto"`
 	Descuento         float64         `xml:"Descuento,attr"`
 	Emisor            Emisor          `xml:"Emisor"`
+	CondicionesDePago string          `xml:"CondicionesDePago,attr"`
 	Fecha             string          `xml:"Fecha,attr"`
 	Folio             string          `xml:"Folio,attr"`
 	FormaPago         string          `xml:"FormaPago,attr"`
+	Impuestos         Impuestos       `xml:"Impuestos"`
+	LugarExpedicion   string          `xml:"LugarExpedicion,attr"`
 	MetodoPago        string          `xml:"MetodoPago,attr"`
 	Moneda            string          `xml:"Moneda,attr"`
 	Receptor          Receptor        `xml:"Receptor"`
@@ -21,24 +26,110 @@ type CFDI struct {
 	Total             float64         `xml:"Total,attr"`
 	Version           string          `xml:"Version,attr"`
 	XMLName           xml.Name        `xml:"Comprobante"`
+
+	// Sello del emisor y su certificado en base64
+	Sello         string `xml:"Sello,attr"`
+	NoCertificado string `xml:"NoCertificado,attr"`
+	Certificado   string `xml:"Certificado,attr"`
+
+	// Path es la ruta del archivo XML de donde se leyó el comprobante
+	Path string `xml:"-"`
 }
 
 type ComplementoCFDI struct {
 	TimbreFiscalDigital TimbreFiscalDigital `xml:"TimbreFiscalDigital"`
+	// Pagos solo viene en los comprobantes de tipo P
+	Pagos Pagos20 `xml:"Pagos"`
+	// ImpuestosLocales suman o restan al total fuera de los impuestos federales
+	ImpuestosLocales ImpuestosLocales `xml:"ImpuestosLocales"`
+}
+
+type ImpuestosLocales struct {
+	TotalTraslados   float64 `xml:"TotaldeTraslados,attr"`
+	TotalRetenciones float64 `xml:"TotaldeRetenciones,attr"`
 }
 
 type TimbreFiscalDigital struct {
-	UUID          string `xml:"UUID,attr"`
-	FechaTimbrado string `xml:"FechaTimbrado,attr"`
+	Version          string `xml:"Version,attr"`
+	UUID             string `xml:"UUID,attr"`
+	FechaTimbrado    string `xml:"FechaTimbrado,attr"`
+	RfcProvCertif    string `xml:"RfcProvCertif,attr"`
+	Leyenda          string `xml:"Leyenda,attr"`
+	SelloCFD         string `xml:"SelloCFD,attr"`
+	NoCertificadoSAT string `xml:"NoCertificadoSAT,attr"`
+	SelloSAT         string `xml:"SelloSAT,attr"`
 }
 
 type Receptor struct {
 	RFC     string `xml:"Rfc,attr"`
 	Nombre  string `xml:"Nombre,attr"`
 	UsoCFDI string `xml:"UsoCFDI,attr"`
+	// RegimenFiscal y DomicilioFiscal solo vienen desde el CFDI 4.0
+	RegimenFiscal   string `xml:"RegimenFiscalReceptor,attr"`
+	DomicilioFiscal string `xml:"DomicilioFiscalReceptor,attr"`
 }
 
 type Emisor struct {
-	RFC    string `xml:"Rfc,attr"`
-	Nombre string `xml:"Nombre,attr"`
+	RFC           string `xml:"Rfc,attr"`
+	Nombre        string `xml:"Nombre,attr"`
+	RegimenFiscal string `xml:"RegimenFiscal,attr"`
+}
+
+type Concepto struct {
+	ClaveProdServ    string  `xml:"ClaveProdServ,attr"`
+	NoIdentificacion string  `xml:"NoIdentificacion,attr"`
+	Cantidad         float64 `xml:"Cantidad,attr"`
+	ClaveUnidad      string  `xml:"ClaveUnidad,attr"`
+	Unidad           string  `xml:"Unidad,attr"`
+	Descripcion      string  `xml:"Descripcion,attr"`
+	ValorUnitario    float64 `xml:"ValorUnitario,attr"`
+	Importe          float64 `xml:"Importe,attr"`
+	Descuento        float64 `xml:"Descuento,attr"`
+}
+
+type Impuestos struct {
+	TotalImpuestosTrasladados float64     `xml:"TotalImpuestosTrasladados,attr"`
+	TotalImpuestosRetenidos   float64     `xml:"TotalImpuestosRetenidos,attr"`
+	Traslados                 []Traslado  `xml:"Traslados>Traslado"`
+	Retenciones               []Retencion `xml:"Retenciones>Retencion"`
+}
+
+type Traslado struct {
+	Base       float64 `xml:"Base,attr"`
+	Impuesto   string  `xml:"Impuesto,attr"`
+	TipoFactor string  `xml:"TipoFactor,attr"`
+	TasaOCuota string  `xml:"TasaOCuota,attr"`
+	Importe    float64 `xml:"Importe,attr"`
+}
+
+type Retencion struct {
+	Impuesto string  `xml:"Impuesto,attr"`
+	Importe  float64 `xml:"Importe,attr"`
+}
+
+// Clave del SAT para el IVA
+const ImpuestoIVA = "002"
+
+// TipoDeCambio regresa el tipo de cambio a pesos, 1 si el comprobante está en
+// pesos o no lo indica
+func (c CFDI) TipoDeCambio() float64 {
+	if c.TipoCambio == "" || c.TipoCambio == "1" {
+		return 1
+	}
+	tc, err := strconv.ParseFloat(c.TipoCambio, 64)
+	if err != nil {
+		return 1
+	}
+	return tc
+}
+
+// IVA regresa la suma de los traslados de IVA del comprobante
+func (c CFDI) IVA() float64 {
+	iva := 0.0
+	for _, t := range c.Impuestos.Traslados {
+		if t.Impuesto == ImpuestoIVA {
+			iva += t.Importe
+		}
+	}
+	return iva
 }
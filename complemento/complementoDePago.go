@@ -19,7 +19,7 @@ type Complemento struct {
 
 type Pagos20 struct {
 	Totales Totales `xml:"Totales"`
-	Pago    Pago    `xml:"Pago"`
+	Pago    []Pago  `xml:"Pago"`
 }
 
 type Totales struct {
@@ -3,6 +3,7 @@ package complemento
 import (
 	"fmt"
 	"log"
+	"sort"
 	"time"
 
 	"github.com/leekchan/accounting"
@@ -23,6 +24,9 @@ type PrintablePago struct {
 
 var ac = accounting.Accounting{Symbol: "$", Precision: 2}
 
+// Formato de las fechas en los atributos del CFDI
+const FechaLayout = "2006-01-02T15:04:05"
+
 func ImprimirPantallaPagos(pago PrintablePagos) {
 	fmt.Println("Emisor: ", pago.Emisor)
 	fmt.Println("Receptor: ", pago.Receptor)
@@ -37,45 +41,100 @@ func ImprimirPantallaPagos(pago PrintablePagos) {
 	fmt.Println("-------------------------------------------------")
 }
 
-func PrintPagos(pagos []PrintablePagos) {
+// PagosMes son los pagos de un mes con sus totales
+type PagosMes struct {
+	// Mes es el primer día del mes
+	Mes      time.Time
+	Pagos    []PrintablePagos
+	Total    float64
+	Facturas int
+}
 
-	lastMonth := time.Month(0)
-	total := 0.0
-	contadorFacturas := 0
+// PagosPorMes agrupa los pagos por el mes de su primer pago, ordenados por mes
+func PagosPorMes(pagos []PrintablePagos) []PagosMes {
+	meses := make([]PagosMes, 0)
+	index := make(map[time.Time]int)
 
 	for _, pago := range pagos {
-		if len(pagos[0].Pagos) == 0 {
-			log.Fatal("No se encontraron pagos")
+		if len(pago.Pagos) == 0 {
+			continue
 		}
 
-		if lastMonth != pago.Pagos[0].FechaPago.Month() {
-			fmt.Println("Total pagos del mes: ", ac.FormatMoney(total))
-			fmt.Println("Total de facturas: ", contadorFacturas)
-			fmt.Println("-------------------------------------------------")
-			fmt.Println()
-			fmt.Println()
-
-			contadorFacturas = 0
-			total = 0.0
-
-			lastMonth = pago.Pagos[0].FechaPago.Month()
-			fmt.Println("-------------------------------------------------")
-			fmt.Println("-------------------------------------------------")
-			fmt.Println("Pagos del mes de ", pago.Pagos[0].FechaPago.Month())
-			fmt.Println("-------------------------------------------------")
-			fmt.Println("-------------------------------------------------")
+		fecha := pago.Pagos[0].FechaPago
+		mes := time.Date(fecha.Year(), fecha.Month(), 1, 0, 0, 0, 0, time.UTC)
+		i, ok := index[mes]
+		if !ok {
+			i = len(meses)
+			index[mes] = i
+			meses = append(meses, PagosMes{Mes: mes})
 		}
 
-		ImprimirPantallaPagos(pago)
-
-		//Total pagos del mes
+		meses[i].Pagos = append(meses[i].Pagos, pago)
 		for _, p := range pago.Pagos {
-			total += p.ImportePagado
-			contadorFacturas++
+			meses[i].Total += p.ImportePagado
+			meses[i].Facturas++
 		}
 	}
 
-	fmt.Println("Total pagos del mes: ", ac.FormatMoney(total))
-	fmt.Println("Total de facturas: ", contadorFacturas)
-	fmt.Println("-------------------------------------------------")
+	sort.SliceStable(meses, func(i, j int) bool {
+		return meses[i].Mes.Before(meses[j].Mes)
+	})
+
+	return meses
+}
+
+// NewPrintablePagos regresa un PrintablePagos por cada pago del complemento,
+// así todos los documentos de un PrintablePagos tienen la misma fecha de pago
+func NewPrintablePagos(emisor, receptor string, fechaTimbrado time.Time, pagos Pagos20) ([]PrintablePagos, error) {
+	printables := make([]PrintablePagos, 0, len(pagos.Pago))
+
+	for _, pago := range pagos.Pago {
+		fechaPago, err := time.Parse(FechaLayout, pago.FechaPago)
+		if err != nil {
+			return nil, err
+		}
+
+		printable := PrintablePagos{
+			Emisor:        emisor,
+			Receptor:      receptor,
+			FechaTimbrado: fechaTimbrado,
+		}
+		for _, documento := range pago.DoctoRelacionado {
+			printable.Pagos = append(printable.Pagos, PrintablePago{
+				FechaPago:     fechaPago,
+				ImportePagado: documento.ImpPagado,
+				Folio:         documento.Folio,
+			})
+		}
+
+		if len(printable.Pagos) > 0 {
+			printables = append(printables, printable)
+		}
+	}
+
+	return printables, nil
+}
+
+func PrintPagos(pagos []PrintablePagos) {
+	if len(pagos) == 0 {
+		log.Fatal("No se encontraron pagos")
+	}
+
+	for _, mes := range PagosPorMes(pagos) {
+		fmt.Println("-------------------------------------------------")
+		fmt.Println("-------------------------------------------------")
+		fmt.Println("Pagos del mes de ", mes.Mes.Month(), mes.Mes.Year())
+		fmt.Println("-------------------------------------------------")
+		fmt.Println("-------------------------------------------------")
+
+		for _, pago := range mes.Pagos {
+			ImprimirPantallaPagos(pago)
+		}
+
+		fmt.Println("Total pagos del mes: ", ac.FormatMoney(mes.Total))
+		fmt.Println("Total de facturas: ", mes.Facturas)
+		fmt.Println("-------------------------------------------------")
+		fmt.Println()
+		fmt.Println()
+	}
 }
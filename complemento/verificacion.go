@@ -0,0 +1,33 @@
+package complemento
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Dirección del servicio del SAT para verificar comprobantes
+const VerificacionSAT = "https://verificacfdi.facturaelectronica.sat.gob.mx/default.aspx"
+
+// VerificacionURL arma la dirección con que se verifica el comprobante en el
+// SAT, es la misma del código QR de la representación impresa
+func (c CFDI) VerificacionURL() string {
+	//El total va sin ceros que no son significativos pero con al menos un decimal
+	total := strconv.FormatFloat(c.Total, 'f', -1, 64)
+	if !strings.Contains(total, ".") {
+		total += ".0"
+	}
+
+	//Los últimos 8 caracteres del sello del emisor
+	sello := strings.Join(strings.Fields(c.Sello), "")
+	if len(sello) > 8 {
+		sello = sello[len(sello)-8:]
+	}
+
+	return VerificacionSAT +
+		"?id=" + c.Complemento.TimbreFiscalDigital.UUID +
+		"&re=" + url.QueryEscape(c.Emisor.RFC) +
+		"&rr=" + url.QueryEscape(c.Receptor.RFC) +
+		"&tt=" + total +
+		"&fe=" + sello
+}
@@ -0,0 +1,420 @@
+package esquema
+
+// Espacios de nombres de los esquemas que se validan
+const (
+	nsCFDI33  = "http://www.sat.gob.mx/cfd/3"
+	nsCFDI40  = "http://www.sat.gob.mx/cfd/4"
+	nsTFD     = "http://www.sat.gob.mx/TimbreFiscalDigital"
+	nsPagos20 = "http://www.sat.gob.mx/Pagos20"
+	nsNomina  = "http://www.sat.gob.mx/nomina12"
+	nsXSI     = "http://www.w3.org/2001/XMLSchema-instance"
+)
+
+// Tipos simples de tdCFDI.xsd y de los catálogos que se revisan por patrón
+var (
+	tTexto         = patron("texto sin |", `[^|]+`)
+	tRFC           = patron("un RFC", `[A-Z&Ñ]{3,4}[0-9]{2}(0[1-9]|1[012])(0[1-9]|[12][0-9]|3[01])[A-Z0-9]{2}[0-9A]`)
+	tFechaH        = patron("una fecha 2006-01-02T15:04:05", `(20[1-9][0-9])-(0[1-9]|1[0-2])-(0[1-9]|[12][0-9]|3[01])T(([01][0-9]|2[0-3]):[0-5][0-9]:[0-5][0-9])`)
+	tFecha         = patron("una fecha 2006-01-02", `([12][0-9]{3})-(0[1-9]|1[0-2])-(0[1-9]|[12][0-9]|3[01])`)
+	tImporte       = patron("un importe con hasta 6 decimales", `[0-9]{1,18}(\.[0-9]{1,6})?`)
+	tDecimal       = patron("un número decimal", `[0-9]{1,18}(\.[0-9]{1,6})?`)
+	tUUID          = patron("un UUID", `[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{12}`)
+	tNoCertificado = patron("un número de certificado de 20 dígitos", `[0-9]{20}`)
+	tBase64        = patron("base64", `[A-Za-z0-9+/=\s]+`)
+	tCodigoPostal  = patron("un código postal", `[0-9]{5}`)
+	tMoneda        = patron("una clave de moneda", `[A-Z]{3}`)
+	tFormaPago     = patron("una forma de pago", `[0-9]{2}`)
+	tRegimen       = patron("un régimen fiscal", `[0-9]{3}`)
+	tUsoCFDI       = patron("un uso CFDI", `[A-Z][0-9]{2}|CP01|CN01|S01`)
+	tClaveProd     = patron("una clave de producto de 8 dígitos", `[0-9]{8}`)
+	tClaveUnidad   = patron("una clave de unidad", `[A-Z0-9]{1,3}`)
+	tObjetoImp     = patron("un objeto de impuesto", `0[1-8]`)
+	tImpuesto      = valores("001, 002 o 003", "001", "002", "003")
+	tTipoFactor    = valores("Tasa, Cuota o Exento", "Tasa", "Cuota", "Exento")
+	tTasaOCuota    = patron("una tasa o cuota con 6 decimales", `[0-9]+\.[0-9]{6}`)
+	tTipoRelacion  = patron("un tipo de relación", `0[1-9]`)
+	tPedimento     = patron("un número de pedimento", `[0-9]{2}  [0-9]{2}  [0-9]{4}  [0-9]{7}`)
+	tTipoCompr     = valores("I, E, T, N o P", "I", "E", "T", "N", "P")
+	tMetodoPago    = valores("PUE o PPD", "PUE", "PPD")
+)
+
+// Nodo raíz de cada esquema, con el nombre de su elemento
+var raices = map[string]string{
+	nsCFDI33 + " Comprobante":      "cfdi33:Comprobante",
+	nsCFDI40 + " Comprobante":      "cfdi40:Comprobante",
+	nsTFD + " TimbreFiscalDigital": "tfd:TimbreFiscalDigital",
+	nsPagos20 + " Pagos":           "pago20:Pagos",
+	nsNomina + " Nomina":           "nomina12:Nomina",
+}
+
+// elementos son los complexType de cada esquema por nombre
+var elementos = map[string]elemento{}
+
+func init() {
+	for name, e := range cfdi40 {
+		elementos[name] = e
+	}
+	for name, e := range cfdi33 {
+		elementos[name] = e
+	}
+	for name, e := range complementos {
+		elementos[name] = e
+	}
+}
+
+// cfdv40.xsd
+var cfdi40 = map[string]elemento{
+	"cfdi40:Comprobante": {
+		namespace: nsCFDI40,
+		atributos: []atributo{
+			req("Version", valores("4.0", "4.0")),
+			opt("Serie", patron("una serie de hasta 25 caracteres", `[^|]{1,25}`)),
+			opt("Folio", patron("un folio de hasta 40 caracteres", `[^|]{1,40}`)),
+			req("Fecha", tFechaH),
+			req("Sello", tBase64),
+			opt("FormaPago", tFormaPago),
+			req("NoCertificado", tNoCertificado),
+			req("Certificado", tBase64),
+			opt("CondicionesDePago", tTexto),
+			req("SubTotal", tImporte),
+			opt("Descuento", tImporte),
+			req("Moneda", tMoneda),
+			opt("TipoCambio", tDecimal),
+			req("Total", tImporte),
+			req("TipoDeComprobante", tTipoCompr),
+			req("Exportacion", patron("una clave de exportación", `0[1-4]`)),
+			opt("MetodoPago", tMetodoPago),
+			req("LugarExpedicion", tCodigoPostal),
+			opt("Confirmacion", patron("una clave de confirmación", `[0-9a-zA-Z]{5}`)),
+		},
+		hijos: []hijo{
+			opcional("InformacionGlobal", "cfdi40:InformacionGlobal"),
+			varios("CfdiRelacionados", "cfdi40:CfdiRelacionados", 0),
+			uno("Emisor", "cfdi40:Emisor"),
+			uno("Receptor", "cfdi40:Receptor"),
+			uno("Conceptos", "cfdi40:Conceptos"),
+			opcional("Impuestos", "cfdi40:Impuestos"),
+			opcional("Complemento", "cfdi:Complemento"),
+			opcional("Addenda", "cfdi:Addenda"),
+		},
+	},
+	"cfdi40:InformacionGlobal": {
+		namespace: nsCFDI40,
+		atributos: []atributo{
+			req("Periodicidad", patron("una periodicidad", `0[1-5]`)),
+			req("Meses", patron("una clave de meses", `0[1-9]|1[0-8]`)),
+			req("Año", patron("un año", `20[0-9]{2}`)),
+		},
+	},
+	"cfdi40:CfdiRelacionados": {
+		namespace: nsCFDI40,
+		atributos: []atributo{req("TipoRelacion", tTipoRelacion)},
+		hijos:     []hijo{varios("CfdiRelacionado", "cfdi40:CfdiRelacionado", 1)},
+	},
+	"cfdi40:CfdiRelacionado": {
+		namespace: nsCFDI40,
+		atributos: []atributo{req("UUID", tUUID)},
+	},
+	"cfdi40:Emisor": {
+		namespace: nsCFDI40,
+		atributos: []atributo{
+			req("Rfc", tRFC),
+			req("Nombre", tTexto),
+			req("RegimenFiscal", tRegimen),
+			opt("FacAtrAdquirente", patron("un número de operación de 10 dígitos", `[0-9]{10}`)),
+		},
+	},
+	"cfdi40:Receptor": {
+		namespace: nsCFDI40,
+		atributos: []atributo{
+			req("Rfc", tRFC),
+			req("Nombre", tTexto),
+			req("DomicilioFiscalReceptor", tCodigoPostal),
+			opt("ResidenciaFiscal", tMoneda),
+			opt("NumRegIdTrib", tTexto),
+			req("RegimenFiscalReceptor", tRegimen),
+			req("UsoCFDI", tUsoCFDI),
+		},
+	},
+	"cfdi40:Conceptos": {
+		namespace: nsCFDI40,
+		hijos:     []hijo{varios("Concepto", "cfdi40:Concepto", 1)},
+	},
+	"cfdi40:Concepto": {
+		namespace: nsCFDI40,
+		atributos: []atributo{
+			req("ClaveProdServ", tClaveProd),
+			opt("NoIdentificacion", tTexto),
+			req("Cantidad", tDecimal),
+			req("ClaveUnidad", tClaveUnidad),
+			opt("Unidad", tTexto),
+			req("Descripcion", tTexto),
+			req("ValorUnitario", tImporte),
+			req("Importe", tImporte),
+			opt("Descuento", tImporte),
+			req("ObjetoImp", tObjetoImp),
+		},
+		hijos: []hijo{
+			opcional("Impuestos", "cfdi40:ConceptoImpuestos"),
+			opcional("ACuentaTerceros", "cfdi40:ACuentaTerceros"),
+			varios("InformacionAduanera", "cfdi40:InformacionAduanera", 0),
+			varios("CuentaPredial", "cfdi40:CuentaPredial", 0),
+			opcional("ComplementoConcepto", "cfdi:Complemento"),
+			varios("Parte", "cfdi40:Parte", 0),
+		},
+	},
+	"cfdi40:ConceptoImpuestos": {
+		namespace: nsCFDI40,
+		hijos: []hijo{
+			opcional("Traslados", "cfdi40:ConceptoTraslados"),
+			opcional("Retenciones", "cfdi40:ConceptoRetenciones"),
+		},
+	},
+	"cfdi40:ConceptoTraslados": {
+		namespace: nsCFDI40,
+		hijos:     []hijo{varios("Traslado", "cfdi40:Traslado", 1)},
+	},
+	"cfdi40:ConceptoRetenciones": {
+		namespace: nsCFDI40,
+		hijos:     []hijo{varios("Retencion", "cfdi40:ConceptoRetencion", 1)},
+	},
+	"cfdi40:Traslado": {
+		namespace: nsCFDI40,
+		atributos: []atributo{
+			req("Base", tImporte),
+			req("Impuesto", tImpuesto),
+			req("TipoFactor", tTipoFactor),
+			opt("TasaOCuota", tTasaOCuota),
+			opt("Importe", tImporte),
+		},
+	},
+	"cfdi40:ConceptoRetencion": {
+		namespace: nsCFDI40,
+		atributos: []atributo{
+			req("Base", tImporte),
+			req("Impuesto", tImpuesto),
+			req("TipoFactor", tTipoFactor),
+			req("TasaOCuota", tTasaOCuota),
+			req("Importe", tImporte),
+		},
+	},
+	"cfdi40:ACuentaTerceros": {
+		namespace: nsCFDI40,
+		atributos: []atributo{
+			req("RfcACuentaTerceros", tRFC),
+			req("NombreACuentaTerceros", tTexto),
+			req("RegimenFiscalACuentaTerceros", tRegimen),
+			req("DomicilioFiscalACuentaTerceros", tCodigoPostal),
+		},
+	},
+	"cfdi40:InformacionAduanera": {
+		namespace: nsCFDI40,
+		atributos: []atributo{req("NumeroPedimento", tPedimento)},
+	},
+	"cfdi40:CuentaPredial": {
+		namespace: nsCFDI40,
+		atributos: []atributo{req("Numero", patron("un número de cuenta predial", `[0-9a-zA-Z]{1,150}`))},
+	},
+	"cfdi40:Parte": {
+		namespace: nsCFDI40,
+		atributos: []atributo{
+			req("ClaveProdServ", tClaveProd),
+			opt("NoIdentificacion", tTexto),
+			req("Cantidad", tDecimal),
+			opt("Unidad", tTexto),
+			req("Descripcion", tTexto),
+			opt("ValorUnitario", tImporte),
+			opt("Importe", tImporte),
+		},
+		hijos: []hijo{varios("InformacionAduanera", "cfdi40:InformacionAduanera", 0)},
+	},
+	"cfdi40:Impuestos": {
+		namespace: nsCFDI40,
+		atributos: []atributo{
+			opt("TotalImpuestosRetenidos", tImporte),
+			opt("TotalImpuestosTrasladados", tImporte),
+		},
+		hijos: []hijo{
+			opcional("Retenciones", "cfdi40:Retenciones"),
+			opcional("Traslados", "cfdi40:ConceptoTraslados"),
+		},
+	},
+	"cfdi40:Retenciones": {
+		namespace: nsCFDI40,
+		hijos:     []hijo{varios("Retencion", "cfdi40:Retencion", 1)},
+	},
+	"cfdi40:Retencion": {
+		namespace: nsCFDI40,
+		atributos: []atributo{
+			req("Impuesto", tImpuesto),
+			req("Importe", tImporte),
+		},
+	},
+	//Complemento y Addenda admiten nodos de cualquier esquema
+	"cfdi:Complemento": {libre: true},
+	"cfdi:Addenda":     {libre: true},
+}
+
+// cfdv33.xsd, los nodos iguales a los de 4.0 cambian solo de espacio de nombres
+var cfdi33 = map[string]elemento{
+	"cfdi33:Comprobante": {
+		namespace: nsCFDI33,
+		atributos: []atributo{
+			req("Version", valores("3.3", "3.3")),
+			opt("Serie", patron("una serie de hasta 25 caracteres", `[^|]{1,25}`)),
+			opt("Folio", patron("un folio de hasta 40 caracteres", `[^|]{1,40}`)),
+			req("Fecha", tFechaH),
+			req("Sello", tBase64),
+			opt("FormaPago", tFormaPago),
+			req("NoCertificado", tNoCertificado),
+			req("Certificado", tBase64),
+			opt("CondicionesDePago", tTexto),
+			req("SubTotal", tImporte),
+			opt("Descuento", tImporte),
+			req("Moneda", tMoneda),
+			opt("TipoCambio", tDecimal),
+			req("Total", tImporte),
+			req("TipoDeComprobante", tTipoCompr),
+			opt("MetodoPago", tMetodoPago),
+			req("LugarExpedicion", tCodigoPostal),
+			opt("Confirmacion", patron("una clave de confirmación", `[0-9a-zA-Z]{5}`)),
+		},
+		hijos: []hijo{
+			opcional("CfdiRelacionados", "cfdi33:CfdiRelacionados"),
+			uno("Emisor", "cfdi33:Emisor"),
+			uno("Receptor", "cfdi33:Receptor"),
+			uno("Conceptos", "cfdi33:Conceptos"),
+			opcional("Impuestos", "cfdi33:Impuestos"),
+			opcional("Complemento", "cfdi:Complemento"),
+			opcional("Addenda", "cfdi:Addenda"),
+		},
+	},
+	"cfdi33:CfdiRelacionados": {
+		namespace: nsCFDI33,
+		atributos: []atributo{req("TipoRelacion", tTipoRelacion)},
+		hijos:     []hijo{varios("CfdiRelacionado", "cfdi33:CfdiRelacionado", 1)},
+	},
+	"cfdi33:CfdiRelacionado": {
+		namespace: nsCFDI33,
+		atributos: []atributo{req("UUID", tUUID)},
+	},
+	"cfdi33:Emisor": {
+		namespace: nsCFDI33,
+		atributos: []atributo{
+			req("Rfc", tRFC),
+			opt("Nombre", tTexto),
+			req("RegimenFiscal", tRegimen),
+		},
+	},
+	"cfdi33:Receptor": {
+		namespace: nsCFDI33,
+		atributos: []atributo{
+			req("Rfc", tRFC),
+			opt("Nombre", tTexto),
+			opt("ResidenciaFiscal", tMoneda),
+			opt("NumRegIdTrib", tTexto),
+			req("UsoCFDI", tUsoCFDI),
+		},
+	},
+	"cfdi33:Conceptos": {
+		namespace: nsCFDI33,
+		hijos:     []hijo{varios("Concepto", "cfdi33:Concepto", 1)},
+	},
+	"cfdi33:Concepto": {
+		namespace: nsCFDI33,
+		atributos: []atributo{
+			req("ClaveProdServ", tClaveProd),
+			opt("NoIdentificacion", tTexto),
+			req("Cantidad", tDecimal),
+			req("ClaveUnidad", tClaveUnidad),
+			opt("Unidad", tTexto),
+			req("Descripcion", tTexto),
+			req("ValorUnitario", tImporte),
+			req("Importe", tImporte),
+			opt("Descuento", tImporte),
+		},
+		hijos: []hijo{
+			opcional("Impuestos", "cfdi33:ConceptoImpuestos"),
+			varios("InformacionAduanera", "cfdi33:InformacionAduanera", 0),
+			opcional("CuentaPredial", "cfdi33:CuentaPredial"),
+			opcional("ComplementoConcepto", "cfdi:Complemento"),
+			varios("Parte", "cfdi33:Parte", 0),
+		},
+	},
+	"cfdi33:ConceptoImpuestos": {
+		namespace: nsCFDI33,
+		hijos: []hijo{
+			opcional("Traslados", "cfdi33:ConceptoTraslados"),
+			opcional("Retenciones", "cfdi33:ConceptoRetenciones"),
+		},
+	},
+	"cfdi33:ConceptoTraslados": {
+		namespace: nsCFDI33,
+		hijos:     []hijo{varios("Traslado", "cfdi33:ConceptoTraslado", 1)},
+	},
+	"cfdi33:ConceptoRetenciones": {
+		namespace: nsCFDI33,
+		hijos:     []hijo{varios("Retencion", "cfdi33:ConceptoRetencion", 1)},
+	},
+	"cfdi33:ConceptoTraslado": {
+		namespace: nsCFDI33,
+		atributos: atributosDe("cfdi40:Traslado"),
+	},
+	"cfdi33:ConceptoRetencion": {
+		namespace: nsCFDI33,
+		atributos: atributosDe("cfdi40:ConceptoRetencion"),
+	},
+	"cfdi33:InformacionAduanera": {
+		namespace: nsCFDI33,
+		atributos: []atributo{req("NumeroPedimento", tPedimento)},
+	},
+	"cfdi33:CuentaPredial": {
+		namespace: nsCFDI33,
+		atributos: []atributo{req("Numero", patron("un número de cuenta predial", `[0-9]{1,150}`))},
+	},
+	"cfdi33:Parte": {
+		namespace: nsCFDI33,
+		atributos: atributosDe("cfdi40:Parte"),
+		hijos:     []hijo{varios("InformacionAduanera", "cfdi33:InformacionAduanera", 0)},
+	},
+	"cfdi33:Impuestos": {
+		namespace: nsCFDI33,
+		atributos: []atributo{
+			opt("TotalImpuestosRetenidos", tImporte),
+			opt("TotalImpuestosTrasladados", tImporte),
+		},
+		hijos: []hijo{
+			opcional("Retenciones", "cfdi33:Retenciones"),
+			opcional("Traslados", "cfdi33:Traslados"),
+		},
+	},
+	"cfdi33:Retenciones": {
+		namespace: nsCFDI33,
+		hijos:     []hijo{varios("Retencion", "cfdi33:Retencion", 1)},
+	},
+	"cfdi33:Retencion": {
+		namespace: nsCFDI33,
+		atributos: []atributo{
+			req("Impuesto", tImpuesto),
+			req("Importe", tImporte),
+		},
+	},
+	"cfdi33:Traslados": {
+		namespace: nsCFDI33,
+		hijos:     []hijo{varios("Traslado", "cfdi33:Traslado", 1)},
+	},
+	"cfdi33:Traslado": {
+		namespace: nsCFDI33,
+		atributos: []atributo{
+			req("Impuesto", tImpuesto),
+			req("TipoFactor", tTipoFactor),
+			req("TasaOCuota", tTasaOCuota),
+			req("Importe", tImporte),
+		},
+	},
+}
+
+// atributosDe toma los atributos de un nodo de 4.0 que no cambió en 3.3
+func atributosDe(name string) []atributo {
+	return cfdi40[name].atributos
+}
@@ -0,0 +1,354 @@
+package esquema
+
+// Tipos de los complementos
+var (
+	tCURP      = patron("una CURP", `[A-Z][AEIOUX][A-Z]{2}[0-9]{2}(0[1-9]|1[0-2])(0[1-9]|[12][0-9]|3[01])[MH][A-Z]{2}[B-DF-HJ-NP-TV-Z]{3}[0-9A-Z][0-9]`)
+	tClaveNom  = patron("una clave de 3 dígitos", `[0-9]{3}`)
+	tEntero    = patron("un número entero", `[0-9]+`)
+	tSiNo      = valores("Sí o No", "Sí", "No")
+	tImporteMx = patron("un importe con 2 decimales", `[0-9]{1,18}(\.[0-9]{1,2})?`)
+)
+
+var complementos = map[string]elemento{
+	// TimbreFiscalDigitalv11.xsd
+	"tfd:TimbreFiscalDigital": {
+		namespace: nsTFD,
+		atributos: []atributo{
+			req("Version", valores("1.1", "1.1")),
+			req("UUID", tUUID),
+			req("FechaTimbrado", tFechaH),
+			req("RfcProvCertif", tRFC),
+			opt("Leyenda", tTexto),
+			req("SelloCFD", tBase64),
+			req("NoCertificadoSAT", tNoCertificado),
+			req("SelloSAT", tBase64),
+		},
+	},
+
+	// Pagos20.xsd
+	"pago20:Pagos": {
+		namespace: nsPagos20,
+		atributos: []atributo{req("Version", valores("2.0", "2.0"))},
+		hijos: []hijo{
+			uno("Totales", "pago20:Totales"),
+			varios("Pago", "pago20:Pago", 1),
+		},
+	},
+	"pago20:Totales": {
+		namespace: nsPagos20,
+		atributos: []atributo{
+			opt("TotalRetencionesIVA", tImporteMx),
+			opt("TotalRetencionesISR", tImporteMx),
+			opt("TotalRetencionesIEPS", tImporteMx),
+			opt("TotalTrasladosBaseIVA16", tImporteMx),
+			opt("TotalTrasladosImpuestoIVA16", tImporteMx),
+			opt("TotalTrasladosBaseIVA8", tImporteMx),
+			opt("TotalTrasladosImpuestoIVA8", tImporteMx),
+			opt("TotalTrasladosBaseIVA0", tImporteMx),
+			opt("TotalTrasladosImpuestoIVA0", tImporteMx),
+			opt("TotalTrasladosBaseIVAExento", tImporteMx),
+			req("MontoTotalPagos", tImporteMx),
+		},
+	},
+	"pago20:Pago": {
+		namespace: nsPagos20,
+		atributos: []atributo{
+			req("FechaPago", tFechaH),
+			req("FormaDePagoP", tFormaPago),
+			req("MonedaP", tMoneda),
+			opt("TipoCambioP", tDecimal),
+			req("Monto", tImporte),
+			opt("NumOperacion", tTexto),
+			opt("RfcEmisorCtaOrd", patron("un RFC", `XEXX010101000|[A-Z&Ñ]{3}[0-9]{2}(0[1-9]|1[012])(0[1-9]|[12][0-9]|3[01])[A-Z0-9]{2}[0-9A]`)),
+			opt("NomBancoOrdExt", tTexto),
+			opt("CtaOrdenante", patron("una cuenta", `[A-Z0-9_]{10,50}`)),
+			opt("RfcEmisorCtaBen", tRFC),
+			opt("CtaBeneficiario", patron("una cuenta", `[A-Z0-9_]{10,50}`)),
+			opt("TipoCadPago", valores("01", "01")),
+			opt("CertPago", tBase64),
+			opt("CadPago", tTexto),
+			opt("SelloPago", tBase64),
+		},
+		hijos: []hijo{
+			varios("DoctoRelacionado", "pago20:DoctoRelacionado", 1),
+			opcional("ImpuestosP", "pago20:ImpuestosP"),
+		},
+	},
+	"pago20:DoctoRelacionado": {
+		namespace: nsPagos20,
+		atributos: []atributo{
+			req("IdDocumento", patron("un UUID o folio", `([a-f0-9A-F]{8}-[a-f0-9A-F]{4}-[a-f0-9A-F]{4}-[a-f0-9A-F]{4}-[a-f0-9A-F]{12})|([0-9]{3}-[0-9]{2}-[0-9]{9})`)),
+			opt("Serie", patron("una serie de hasta 25 caracteres", `[^|]{1,25}`)),
+			opt("Folio", patron("un folio de hasta 40 caracteres", `[^|]{1,40}`)),
+			req("MonedaDR", tMoneda),
+			opt("EquivalenciaDR", tDecimal),
+			req("NumParcialidad", tEntero),
+			req("ImpSaldoAnt", tImporte),
+			req("ImpPagado", tImporte),
+			req("ImpSaldoInsoluto", tImporte),
+			req("ObjetoImpDR", tObjetoImp),
+		},
+		hijos: []hijo{opcional("ImpuestosDR", "pago20:ImpuestosDR")},
+	},
+	"pago20:ImpuestosDR": {
+		namespace: nsPagos20,
+		hijos: []hijo{
+			opcional("RetencionesDR", "pago20:RetencionesDR"),
+			opcional("TrasladosDR", "pago20:TrasladosDR"),
+		},
+	},
+	"pago20:RetencionesDR": {
+		namespace: nsPagos20,
+		hijos:     []hijo{varios("RetencionDR", "pago20:RetencionDR", 1)},
+	},
+	"pago20:RetencionDR": {
+		namespace: nsPagos20,
+		atributos: []atributo{
+			req("BaseDR", tImporte),
+			req("ImpuestoDR", tImpuesto),
+			req("TipoFactorDR", tTipoFactor),
+			req("TasaOCuotaDR", tTasaOCuota),
+			req("ImporteDR", tImporte),
+		},
+	},
+	"pago20:TrasladosDR": {
+		namespace: nsPagos20,
+		hijos:     []hijo{varios("TrasladoDR", "pago20:TrasladoDR", 1)},
+	},
+	"pago20:TrasladoDR": {
+		namespace: nsPagos20,
+		atributos: []atributo{
+			req("BaseDR", tImporte),
+			req("ImpuestoDR", tImpuesto),
+			req("TipoFactorDR", tTipoFactor),
+			opt("TasaOCuotaDR", tTasaOCuota),
+			opt("ImporteDR", tImporte),
+		},
+	},
+	"pago20:ImpuestosP": {
+		namespace: nsPagos20,
+		hijos: []hijo{
+			opcional("RetencionesP", "pago20:RetencionesP"),
+			opcional("TrasladosP", "pago20:TrasladosP"),
+		},
+	},
+	"pago20:RetencionesP": {
+		namespace: nsPagos20,
+		hijos:     []hijo{varios("RetencionP", "pago20:RetencionP", 1)},
+	},
+	"pago20:RetencionP": {
+		namespace: nsPagos20,
+		atributos: []atributo{
+			req("ImpuestoP", tImpuesto),
+			req("ImporteP", tImporte),
+		},
+	},
+	"pago20:TrasladosP": {
+		namespace: nsPagos20,
+		hijos:     []hijo{varios("TrasladoP", "pago20:TrasladoP", 1)},
+	},
+	"pago20:TrasladoP": {
+		namespace: nsPagos20,
+		atributos: []atributo{
+			req("BaseP", tImporte),
+			req("ImpuestoP", tImpuesto),
+			req("TipoFactorP", tTipoFactor),
+			opt("TasaOCuotaP", tTasaOCuota),
+			opt("ImporteP", tImporte),
+		},
+	},
+
+	// nomina12.xsd
+	"nomina12:Nomina": {
+		namespace: nsNomina,
+		atributos: []atributo{
+			req("Version", valores("1.2", "1.2")),
+			req("TipoNomina", valores("O o E", "O", "E")),
+			req("FechaPago", tFecha),
+			req("FechaInicialPago", tFecha),
+			req("FechaFinalPago", tFecha),
+			req("NumDiasPagados", tDecimal),
+			opt("TotalPercepciones", tImporteMx),
+			opt("TotalDeducciones", tImporteMx),
+			opt("TotalOtrosPagos", tImporteMx),
+		},
+		hijos: []hijo{
+			opcional("Emisor", "nomina12:Emisor"),
+			uno("Receptor", "nomina12:Receptor"),
+			opcional("Percepciones", "nomina12:Percepciones"),
+			opcional("Deducciones", "nomina12:Deducciones"),
+			opcional("OtrosPagos", "nomina12:OtrosPagos"),
+			opcional("Incapacidades", "nomina12:Incapacidades"),
+		},
+	},
+	"nomina12:Emisor": {
+		namespace: nsNomina,
+		atributos: []atributo{
+			opt("Curp", tCURP),
+			opt("RegistroPatronal", tTexto),
+			opt("RfcPatronOrigen", tRFC),
+		},
+		hijos: []hijo{opcional("EntidadSNCF", "nomina12:EntidadSNCF")},
+	},
+	"nomina12:EntidadSNCF": {
+		namespace: nsNomina,
+		atributos: []atributo{
+			req("OrigenRecurso", valores("IP, IF o IM", "IP", "IF", "IM")),
+			opt("MontoRecursoPropio", tImporteMx),
+		},
+	},
+	"nomina12:Receptor": {
+		namespace: nsNomina,
+		atributos: []atributo{
+			req("Curp", tCURP),
+			opt("NumSeguridadSocial", patron("un número de seguridad social", `[0-9]{1,15}`)),
+			opt("FechaInicioRelLaboral", tFecha),
+			opt("Antigüedad", patron("una antigüedad como P52W", `P(([1-9][0-9]{0,3})|0)W|P([1-9][0-9]?Y)?(([1-9]|1[012])M)?(0|[1-9]|[12][0-9]|3[01])D`)),
+			req("TipoContrato", tTexto),
+			opt("Sindicalizado", tSiNo),
+			opt("TipoJornada", tTexto),
+			req("TipoRegimen", tTexto),
+			req("NumEmpleado", tTexto),
+			opt("Departamento", tTexto),
+			opt("Puesto", tTexto),
+			opt("RiesgoPuesto", tTexto),
+			req("PeriodicidadPago", tTexto),
+			opt("Banco", tClaveNom),
+			opt("CuentaBancaria", patron("una cuenta bancaria", `[0-9]{10,11}|[0-9]{15,16}|[0-9]{18}|[A-Z0-9_]{10,50}`)),
+			opt("SalarioBaseCotApor", tImporteMx),
+			opt("SalarioDiarioIntegrado", tImporteMx),
+			req("ClaveEntFed", tTexto),
+		},
+		hijos: []hijo{varios("SubContratacion", "nomina12:SubContratacion", 0)},
+	},
+	"nomina12:SubContratacion": {
+		namespace: nsNomina,
+		atributos: []atributo{
+			req("RfcLabora", tRFC),
+			req("PorcentajeTiempo", tDecimal),
+		},
+	},
+	"nomina12:Percepciones": {
+		namespace: nsNomina,
+		atributos: []atributo{
+			opt("TotalSueldos", tImporteMx),
+			opt("TotalSeparacionIndemnizacion", tImporteMx),
+			opt("TotalJubilacionPensionRetiro", tImporteMx),
+			req("TotalGravado", tImporteMx),
+			req("TotalExento", tImporteMx),
+		},
+		hijos: []hijo{
+			varios("Percepcion", "nomina12:Percepcion", 1),
+			opcional("JubilacionPensionRetiro", "nomina12:JubilacionPensionRetiro"),
+			opcional("SeparacionIndemnizacion", "nomina12:SeparacionIndemnizacion"),
+		},
+	},
+	"nomina12:Percepcion": {
+		namespace: nsNomina,
+		atributos: []atributo{
+			req("TipoPercepcion", tClaveNom),
+			req("Clave", patron("una clave de 3 a 15 caracteres", `[^|]{3,15}`)),
+			req("Concepto", tTexto),
+			req("ImporteGravado", tImporteMx),
+			req("ImporteExento", tImporteMx),
+		},
+		hijos: []hijo{
+			opcional("AccionesOTitulos", "nomina12:AccionesOTitulos"),
+			varios("HorasExtra", "nomina12:HorasExtra", 0),
+		},
+	},
+	"nomina12:AccionesOTitulos": {
+		namespace: nsNomina,
+		atributos: []atributo{
+			req("ValorMercado", tImporte),
+			req("PrecioAlOtorgarse", tImporte),
+		},
+	},
+	"nomina12:HorasExtra": {
+		namespace: nsNomina,
+		atributos: []atributo{
+			req("Dias", tEntero),
+			req("TipoHoras", valores("01, 02 o 03", "01", "02", "03")),
+			req("HorasExtra", tEntero),
+			req("ImportePagado", tImporteMx),
+		},
+	},
+	"nomina12:JubilacionPensionRetiro": {
+		namespace: nsNomina,
+		atributos: []atributo{
+			opt("TotalUnaExhibicion", tImporteMx),
+			opt("TotalParcialidad", tImporteMx),
+			opt("MontoDiario", tImporteMx),
+			req("IngresoAcumulable", tImporteMx),
+			req("IngresoNoAcumulable", tImporteMx),
+		},
+	},
+	"nomina12:SeparacionIndemnizacion": {
+		namespace: nsNomina,
+		atributos: []atributo{
+			req("TotalPagado", tImporteMx),
+			req("NumAñosServicio", tEntero),
+			req("UltimoSueldoMensOrd", tImporteMx),
+			req("IngresoAcumulable", tImporteMx),
+			req("IngresoNoAcumulable", tImporteMx),
+		},
+	},
+	"nomina12:Deducciones": {
+		namespace: nsNomina,
+		atributos: []atributo{
+			opt("TotalOtrasDeducciones", tImporteMx),
+			opt("TotalImpuestosRetenidos", tImporteMx),
+		},
+		hijos: []hijo{varios("Deduccion", "nomina12:Deduccion", 1)},
+	},
+	"nomina12:Deduccion": {
+		namespace: nsNomina,
+		atributos: []atributo{
+			req("TipoDeduccion", tClaveNom),
+			req("Clave", patron("una clave de 3 a 15 caracteres", `[^|]{3,15}`)),
+			req("Concepto", tTexto),
+			req("Importe", tImporteMx),
+		},
+	},
+	"nomina12:OtrosPagos": {
+		namespace: nsNomina,
+		hijos:     []hijo{varios("OtroPago", "nomina12:OtroPago", 1)},
+	},
+	"nomina12:OtroPago": {
+		namespace: nsNomina,
+		atributos: []atributo{
+			req("TipoOtroPago", tClaveNom),
+			req("Clave", patron("una clave de 3 a 15 caracteres", `[^|]{3,15}`)),
+			req("Concepto", tTexto),
+			req("Importe", tImporteMx),
+		},
+		hijos: []hijo{
+			opcional("SubsidioAlEmpleo", "nomina12:SubsidioAlEmpleo"),
+			opcional("CompensacionSaldosAFavor", "nomina12:CompensacionSaldosAFavor"),
+		},
+	},
+	"nomina12:SubsidioAlEmpleo": {
+		namespace: nsNomina,
+		atributos: []atributo{req("SubsidioCausado", tImporteMx)},
+	},
+	"nomina12:CompensacionSaldosAFavor": {
+		namespace: nsNomina,
+		atributos: []atributo{
+			req("SaldoAFavor", tImporteMx),
+			req("Año", patron("un año", `20[0-9]{2}`)),
+			req("RemanenteSalFav", tImporteMx),
+		},
+	},
+	"nomina12:Incapacidades": {
+		namespace: nsNomina,
+		hijos:     []hijo{varios("Incapacidad", "nomina12:Incapacidad", 1)},
+	},
+	"nomina12:Incapacidad": {
+		namespace: nsNomina,
+		atributos: []atributo{
+			req("DiasIncapacidad", tEntero),
+			req("TipoIncapacidad", tTexto),
+			opt("ImporteMonetario", tImporteMx),
+		},
+	},
+}
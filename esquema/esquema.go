@@ -0,0 +1,270 @@
+package esquema
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+)
+
+// Violation es un error de estructura del XML con la ruta del nodo o atributo
+type Violation struct {
+	XPath   string
+	Mensaje string
+}
+
+func (v Violation) String() string {
+	return v.XPath + ": " + v.Mensaje
+}
+
+// tipo restringe el valor de un atributo con un patrón o una lista de valores,
+// igual que los simpleType de los XSD del SAT
+type tipo struct {
+	nombre  string
+	pattern *regexp.Regexp
+	valores []string
+}
+
+func patron(nombre, expr string) tipo {
+	return tipo{nombre: nombre, pattern: regexp.MustCompile("^(?:" + expr + ")$")}
+}
+
+func valores(nombre string, list ...string) tipo {
+	return tipo{nombre: nombre, valores: list}
+}
+
+func (t tipo) valido(value string) bool {
+	if t.pattern != nil && !t.pattern.MatchString(value) {
+		return false
+	}
+	if len(t.valores) > 0 {
+		for _, v := range t.valores {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+type atributo struct {
+	nombre    string
+	requerido bool
+	tipo      tipo
+}
+
+func req(nombre string, t tipo) atributo {
+	return atributo{nombre: nombre, requerido: true, tipo: t}
+}
+
+func opt(nombre string, t tipo) atributo {
+	return atributo{nombre: nombre, tipo: t}
+}
+
+// hijo es un elemento de la secuencia con cuántas veces puede aparecer,
+// max 0 es sin límite
+type hijo struct {
+	nombre   string
+	elemento string
+	min, max int
+}
+
+func uno(nombre, elemento string) hijo {
+	return hijo{nombre: nombre, elemento: elemento, min: 1, max: 1}
+}
+
+func opcional(nombre, elemento string) hijo {
+	return hijo{nombre: nombre, elemento: elemento, min: 0, max: 1}
+}
+
+func varios(nombre, elemento string, min int) hijo {
+	return hijo{nombre: nombre, elemento: elemento, min: min}
+}
+
+// elemento describe un complexType: sus atributos y la secuencia de hijos. Con
+// libre los hijos pueden ser de cualquier esquema, como en Complemento y Addenda
+type elemento struct {
+	namespace string
+	atributos []atributo
+	hijos     []hijo
+	libre     bool
+}
+
+// Prefijos con que se escriben las rutas de cada espacio de nombres
+var prefijos = map[string]string{
+	nsCFDI33:  "cfdi",
+	nsCFDI40:  "cfdi",
+	nsTFD:     "tfd",
+	nsPagos20: "pago20",
+	nsNomina:  "nomina12",
+}
+
+// ValidateFile valida el XML del archivo
+func ValidateFile(path string) ([]Violation, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Validate(content)
+}
+
+// Validate revisa la estructura del comprobante y de los complementos que
+// conoce contra los esquemas cfdv33, cfdv40, TFD 1.1, Pagos 2.0 y Nómina 1.2.
+// Los complementos de otros esquemas no se revisan
+func Validate(content []byte) ([]Violation, error) {
+	root, err := complemento.ParseNodo(content)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &validator{violations: make([]Violation, 0)}
+	path := "/" + nodeName(root)
+
+	name, ok := raices[root.XMLName.Space+" "+root.XMLName.Local]
+	if !ok || root.XMLName.Local != "Comprobante" {
+		v.add(path, fmt.Sprintf("el nodo raíz no es un comprobante 3.3 o 4.0 (%s)", root.XMLName.Space))
+		return v.violations, nil
+	}
+	v.validate(root, name, path)
+
+	return v.violations, nil
+}
+
+type validator struct {
+	violations []Violation
+}
+
+func (v *validator) add(path, mensaje string) {
+	v.violations = append(v.violations, Violation{XPath: path, Mensaje: mensaje})
+}
+
+func (v *validator) validate(n complemento.Nodo, name, path string) {
+	e := elementos[name]
+
+	v.validateAttrs(n, e, path)
+
+	if e.libre {
+		for i, child := range n.Nodos {
+			//Solo se revisan los complementos de los esquemas conocidos
+			if raiz, ok := raices[child.XMLName.Space+" "+child.XMLName.Local]; ok {
+				v.validate(child, raiz, childPath(path, n.Nodos, i))
+			}
+		}
+		return
+	}
+
+	v.validateChildren(n, e, path)
+}
+
+func (v *validator) validateAttrs(n complemento.Nodo, e elemento, path string) {
+	known := make(map[string]bool, len(e.atributos))
+	for _, a := range e.atributos {
+		known[a.nombre] = true
+
+		value, ok := n.Attr(a.nombre)
+		if !ok {
+			if a.requerido {
+				v.add(path+"/@"+a.nombre, "falta el atributo requerido")
+			}
+			continue
+		}
+		if !a.tipo.valido(value) {
+			v.add(path+"/@"+a.nombre, fmt.Sprintf("el valor %q no es %s", value, a.tipo.nombre))
+		}
+	}
+
+	for _, attr := range n.Attrs {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" || attr.Name.Space == nsXSI {
+			continue
+		}
+		if attr.Name.Space != "" || !known[attr.Name.Local] {
+			v.add(path+"/@"+attr.Name.Local, "atributo no permitido en este nodo")
+		}
+	}
+}
+
+// validateChildren recorre los hijos contra la secuencia, cada hijo debe ir
+// después de los anteriores y respetar cuántas veces puede aparecer
+func (v *validator) validateChildren(n complemento.Nodo, e elemento, path string) {
+	counts := make([]int, len(e.hijos))
+	pos := 0
+
+	for i, child := range n.Nodos {
+		cpath := childPath(path, n.Nodos, i)
+
+		index := -1
+		for j, h := range e.hijos {
+			if h.nombre == child.XMLName.Local && child.XMLName.Space == e.namespace {
+				index = j
+				break
+			}
+		}
+
+		switch {
+		case index < 0:
+			v.add(cpath, "nodo no permitido en "+nodeName(n))
+			continue
+		case index < pos:
+			v.add(cpath, fmt.Sprintf("el nodo debe ir antes de %s", e.hijos[pos].nombre))
+		default:
+			pos = index
+		}
+
+		counts[index]++
+		if h := e.hijos[index]; h.max > 0 && counts[index] > h.max {
+			v.add(cpath, fmt.Sprintf("el nodo aparece más de %d veces", h.max))
+		}
+
+		v.validate(child, e.hijos[index].elemento, cpath)
+	}
+
+	for j, h := range e.hijos {
+		if counts[j] < h.min {
+			v.add(path+"/"+prefixed(e.namespace, h.nombre), "falta el nodo requerido")
+		}
+	}
+}
+
+func nodeName(n complemento.Nodo) string {
+	return prefixed(n.XMLName.Space, n.XMLName.Local)
+}
+
+func prefixed(namespace, local string) string {
+	if prefix, ok := prefijos[namespace]; ok {
+		return prefix + ":" + local
+	}
+	return local
+}
+
+// childPath agrega el nodo a la ruta con su posición si tiene hermanos con el mismo nombre
+func childPath(path string, siblings []complemento.Nodo, i int) string {
+	name := siblings[i].XMLName
+	position, total := 0, 0
+	for j, s := range siblings {
+		if s.XMLName == name {
+			total++
+			if j <= i {
+				position++
+			}
+		}
+	}
+
+	step := path + "/" + prefixed(name.Space, name.Local)
+	if total > 1 {
+		step += fmt.Sprintf("[%d]", position)
+	}
+	return step
+}
+
+// Resumen regresa el texto corto para la tabla, por ejemplo "✗ 3 errores"
+func Resumen(violations []Violation) string {
+	switch len(violations) {
+	case 0:
+		return "✓"
+	case 1:
+		return "✗ 1 error"
+	}
+	return fmt.Sprintf("✗ %d errores", len(violations))
+}
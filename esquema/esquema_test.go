@@ -0,0 +1,197 @@
+package esquema
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func readTestdata(t *testing.T, name string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+// replace cambia el XML de prueba y falla si no encontró el texto
+func replace(t *testing.T, content, old, new string) string {
+	t.Helper()
+	if !strings.Contains(content, old) {
+		t.Fatalf("no se encontró %q en el XML", old)
+	}
+	return strings.Replace(content, old, new, 1)
+}
+
+func xpaths(violations []Violation) []string {
+	paths := make([]string, 0, len(violations))
+	for _, v := range violations {
+		paths = append(paths, v.XPath)
+	}
+	return paths
+}
+
+func TestValidateValidos(t *testing.T) {
+	//Los complementos implocal y leyendasFiscales del 3.3 y la addenda del
+	//4.0 no son de los esquemas conocidos y no se revisan
+	for _, name := range []string{"cfdi33.xml", "cfdi40.xml", "cfdi40_pagos20.xml", "cfdi40_nomina12.xml"} {
+		t.Run(name, func(t *testing.T) {
+			violations, err := Validate([]byte(readTestdata(t, name)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(violations) != 0 {
+				t.Errorf("se esperaba sin errores, hay %v", violations)
+			}
+		})
+	}
+}
+
+func TestValidateErrores(t *testing.T) {
+	const comprobante = "/cfdi:Comprobante"
+
+	tests := []struct {
+		name   string
+		file   string
+		edit   func(t *testing.T, xml string) string
+		xpaths []string
+	}{
+		{
+			name: "falta atributo requerido",
+			file: "cfdi40.xml",
+			edit: func(t *testing.T, xml string) string {
+				return replace(t, xml, ` Exportacion="01"`, "")
+			},
+			xpaths: []string{comprobante + "/@Exportacion"},
+		},
+		{
+			name: "patrón inválido",
+			file: "cfdi40.xml",
+			edit: func(t *testing.T, xml string) string {
+				return replace(t, xml, `Rfc="EKU9003173C9"`, `Rfc="EKU-9003"`)
+			},
+			xpaths: []string{comprobante + "/cfdi:Emisor/@Rfc"},
+		},
+		{
+			name: "valor fuera del catálogo en 3.3",
+			file: "cfdi33.xml",
+			edit: func(t *testing.T, xml string) string {
+				return replace(t, xml, `TipoDeComprobante="I"`, `TipoDeComprobante="X"`)
+			},
+			xpaths: []string{comprobante + "/@TipoDeComprobante"},
+		},
+		{
+			name: "nodo fuera de orden",
+			file: "cfdi40.xml",
+			edit: func(t *testing.T, xml string) string {
+				emisor := `  <cfdi:Emisor RegimenFiscal="601" Rfc="EKU9003173C9" Nombre="ESCUELA KEMPER URGATE"/>` + "\n"
+				xml = replace(t, xml, emisor, "")
+				return replace(t, xml, "  <cfdi:Conceptos>", emisor+"  <cfdi:Conceptos>")
+			},
+			xpaths: []string{comprobante + "/cfdi:Emisor"},
+		},
+		{
+			name: "atributo no permitido",
+			file: "cfdi40.xml",
+			edit: func(t *testing.T, xml string) string {
+				return replace(t, xml, `<cfdi:Concepto ClaveProdServ="01010101"`, `<cfdi:Concepto Color="rojo" ClaveProdServ="01010101"`)
+			},
+			xpaths: []string{comprobante + "/cfdi:Conceptos/cfdi:Concepto[2]/@Color"},
+		},
+		{
+			name: "falta nodo requerido",
+			file: "cfdi33.xml",
+			edit: func(t *testing.T, xml string) string {
+				return replace(t, xml, `  <cfdi:Receptor Rfc="XAXX010101000" UsoCFDI="G03"/>`+"\n", "")
+			},
+			xpaths: []string{comprobante + "/cfdi:Receptor"},
+		},
+		{
+			name: "complemento de esquema desconocido",
+			file: "cfdi40.xml",
+			edit: func(t *testing.T, xml string) string {
+				return replace(t, xml, "<cfdi:Complemento>", `<cfdi:Complemento><cce20:ComercioExterior xmlns:cce20="http://www.sat.gob.mx/ComercioExterior20" Cualquiera="1"><cce20:Otro/></cce20:ComercioExterior>`)
+			},
+			xpaths: []string{},
+		},
+		{
+			name: "timbre sin UUID",
+			file: "cfdi40.xml",
+			edit: func(t *testing.T, xml string) string {
+				return replace(t, xml, ` UUID="6E3A4B2C-1D5F-4A7B-9C8D-0E1F2A3B4C5D"`, "")
+			},
+			xpaths: []string{comprobante + "/cfdi:Complemento/tfd:TimbreFiscalDigital/@UUID"},
+		},
+		{
+			name: "pagos 2.0 sin MontoTotalPagos",
+			file: "cfdi40_pagos20.xml",
+			edit: func(t *testing.T, xml string) string {
+				return replace(t, xml, `MontoTotalPagos="1160.00" `, "")
+			},
+			xpaths: []string{comprobante + "/cfdi:Complemento/pago20:Pagos/pago20:Totales/@MontoTotalPagos"},
+		},
+		{
+			name: "pagos 2.0 sin Pago",
+			file: "cfdi40_pagos20.xml",
+			edit: func(t *testing.T, xml string) string {
+				start := strings.Index(xml, "      <pago20:Pago ")
+				end := strings.Index(xml, "      </pago20:Pago>\n") + len("      </pago20:Pago>\n")
+				if start < 0 || end < start {
+					t.Fatal("no se encontró el nodo Pago")
+				}
+				return xml[:start] + xml[end:]
+			},
+			xpaths: []string{comprobante + "/cfdi:Complemento/pago20:Pagos/pago20:Pago"},
+		},
+		{
+			name: "nómina con catálogo inválido",
+			file: "cfdi40_nomina12.xml",
+			edit: func(t *testing.T, xml string) string {
+				return replace(t, xml, `TipoHoras="01"`, `TipoHoras="Dobles"`)
+			},
+			xpaths: []string{comprobante + "/cfdi:Complemento/nomina12:Nomina/nomina12:Percepciones/nomina12:Percepcion[2]/nomina12:HorasExtra/@TipoHoras"},
+		},
+		{
+			name: "nómina con otros pagos antes de deducciones",
+			file: "cfdi40_nomina12.xml",
+			edit: func(t *testing.T, xml string) string {
+				start := strings.Index(xml, "      <nomina12:Deducciones ")
+				end := strings.Index(xml, "      </nomina12:Deducciones>\n") + len("      </nomina12:Deducciones>\n")
+				if start < 0 || end < start {
+					t.Fatal("no se encontró el nodo Deducciones")
+				}
+				deducciones := xml[start:end]
+				xml = xml[:start] + xml[end:]
+				return replace(t, xml, "      </nomina12:OtrosPagos>\n", "      </nomina12:OtrosPagos>\n"+deducciones)
+			},
+			xpaths: []string{comprobante + "/cfdi:Complemento/nomina12:Nomina/nomina12:Deducciones"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := tt.edit(t, readTestdata(t, tt.file))
+
+			violations, err := Validate([]byte(content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := xpaths(violations); !reflect.DeepEqual(got, tt.xpaths) {
+				t.Errorf("rutas = %v, se esperaba %v\n%v", got, tt.xpaths, violations)
+			}
+		})
+	}
+}
+
+func TestValidateRaiz(t *testing.T) {
+	violations, err := Validate([]byte(`<Factura Version="4.0"/>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := xpaths(violations); !reflect.DeepEqual(got, []string{"/Factura"}) {
+		t.Errorf("rutas = %v, se esperaba [/Factura]", got)
+	}
+}
@@ -0,0 +1,131 @@
+package estatus
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+)
+
+// Cache guarda en un archivo JSON el último estado consultado de cada factura,
+// por UUID
+type Cache struct {
+	path  string
+	items map[string]Result
+}
+
+// OpenCache lee el archivo de estados, si no existe se crea al guardar
+func OpenCache(path string) (*Cache, error) {
+	c := &Cache{
+		path:  path,
+		items: map[string]Result{},
+	}
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, &c.items); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Get regresa el último estado de la factura, un Cache nil no tiene estados
+func (c *Cache) Get(uuid string) (Result, bool) {
+	if c == nil || normalizeUUID(uuid) == "" {
+		return Result{}, false
+	}
+	r, ok := c.items[normalizeUUID(uuid)]
+	return r, ok
+}
+
+// Put guarda el estado de la factura, sin UUID no se guarda porque todas las
+// facturas sin timbrar compartirían el estado
+func (c *Cache) Put(uuid string, r Result) {
+	if normalizeUUID(uuid) == "" {
+		return
+	}
+	c.items[normalizeUUID(uuid)] = r
+}
+
+// Merge guarda el estado si es más reciente que el que ya se tiene, una
+// cancelación siempre se guarda y ya no se reemplaza
+func (c *Cache) Merge(uuid string, r Result) {
+	cached, ok := c.Get(uuid)
+	if ok && cached.Estado == Cancelado {
+		return
+	}
+	if ok && r.Estado != Cancelado && !r.Consultado.After(cached.Consultado) {
+		return
+	}
+	c.Put(uuid, r)
+}
+
+// Save escribe primero un archivo temporal para no dejar el archivo a medias
+func (c *Cache) Save() error {
+	content, err := json.MarshalIndent(c.items, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, c.path)
+}
+
+// Checker consulta el estado de las facturas que no están en el cache o cuya
+// consulta ya venció, una factura cancelada no vuelve a consultarse
+type Checker struct {
+	provider Provider
+	cache    *Cache
+	vigencia time.Duration
+	now      func() time.Time
+}
+
+func NewChecker(provider Provider, cache *Cache, vigencia time.Duration) *Checker {
+	return &Checker{
+		provider: provider,
+		cache:    cache,
+		vigencia: vigencia,
+		now:      time.Now,
+	}
+}
+
+// Check regresa el estado de la factura, si la consulta falla regresa el
+// último estado conocido junto con el error. Una factura sin UUID no se
+// consulta y queda como desconocida
+func (ch *Checker) Check(c complemento.CFDI) (Result, error) {
+	q := ConsultaDe(c)
+	if normalizeUUID(q.UUID) == "" {
+		return Result{}, nil
+	}
+
+	cached, ok := ch.cache.Get(q.UUID)
+	if ok && (cached.Estado == Cancelado || ch.now().Sub(cached.Consultado) < ch.vigencia) {
+		return cached, nil
+	}
+
+	estado, err := ch.provider.Consultar(q)
+	if err != nil {
+		return cached, err
+	}
+
+	r := Result{Estado: estado, Consultado: ch.now()}
+	ch.cache.Put(q.UUID, r)
+	return r, nil
+}
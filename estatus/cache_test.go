@@ -0,0 +1,200 @@
+package estatus
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+)
+
+// fakeProvider regresa siempre el mismo estado y cuenta las consultas
+type fakeProvider struct {
+	estado    Estado
+	err       error
+	consultas int
+}
+
+func (p *fakeProvider) Consultar(q Consulta) (Estado, error) {
+	p.consultas++
+	return p.estado, p.err
+}
+
+const testUUID = "6E3A4B2C-1D5F-4A7B-9C8D-0E1F2A3B4C5D"
+
+func testCFDI() complemento.CFDI {
+	var c complemento.CFDI
+	c.Complemento.TimbreFiscalDigital.UUID = testUUID
+	return c
+}
+
+func TestCheckerCheck(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	errSAT := errors.New("servicio no disponible")
+
+	tests := []struct {
+		name      string
+		cached    *Result
+		provider  fakeProvider
+		want      Result
+		err       error
+		consultas int
+	}{
+		{
+			name:      "sin cache se consulta",
+			provider:  fakeProvider{estado: Vigente},
+			want:      Result{Estado: Vigente, Consultado: now},
+			consultas: 1,
+		},
+		{
+			name:     "consulta vigente no se repite",
+			cached:   &Result{Estado: Vigente, Consultado: now.Add(-time.Hour)},
+			provider: fakeProvider{estado: Cancelado},
+			want:     Result{Estado: Vigente, Consultado: now.Add(-time.Hour)},
+		},
+		{
+			name:      "consulta vencida se repite",
+			cached:    &Result{Estado: Vigente, Consultado: now.Add(-48 * time.Hour)},
+			provider:  fakeProvider{estado: Cancelado},
+			want:      Result{Estado: Cancelado, Consultado: now},
+			consultas: 1,
+		},
+		{
+			name:     "cancelada no se vuelve a consultar",
+			cached:   &Result{Estado: Cancelado, Consultado: now.Add(-365 * 24 * time.Hour)},
+			provider: fakeProvider{estado: Vigente},
+			want:     Result{Estado: Cancelado, Consultado: now.Add(-365 * 24 * time.Hour)},
+		},
+		{
+			name:      "error regresa el último estado",
+			cached:    &Result{Estado: Vigente, Consultado: now.Add(-48 * time.Hour)},
+			provider:  fakeProvider{err: errSAT},
+			want:      Result{Estado: Vigente, Consultado: now.Add(-48 * time.Hour)},
+			err:       errSAT,
+			consultas: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, err := OpenCache(filepath.Join(t.TempDir(), "estados.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.cached != nil {
+				cache.Put(testUUID, *tt.cached)
+			}
+
+			provider := tt.provider
+			checker := NewChecker(&provider, cache, 24*time.Hour)
+			checker.now = func() time.Time { return now }
+
+			got, err := checker.Check(testCFDI())
+			if !errors.Is(err, tt.err) {
+				t.Errorf("error = %v, se esperaba %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("estado = %+v, se esperaba %+v", got, tt.want)
+			}
+			if provider.consultas != tt.consultas {
+				t.Errorf("%d consultas, se esperaban %d", provider.consultas, tt.consultas)
+			}
+
+			//El error no cambia lo que ya estaba guardado
+			if cached, _ := cache.Get(testUUID); cached != tt.want {
+				t.Errorf("cache = %+v, se esperaba %+v", cached, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckerSinUUID(t *testing.T) {
+	provider := &fakeProvider{estado: Vigente}
+	checker := NewChecker(provider, &Cache{items: map[string]Result{}}, time.Hour)
+
+	r, err := checker.Check(complemento.CFDI{})
+	if err != nil || r != (Result{}) || provider.consultas != 0 {
+		t.Errorf("sin UUID = %+v, %v, %d consultas; se esperaba sin consultar", r, err, provider.consultas)
+	}
+}
+
+func TestCacheMerge(t *testing.T) {
+	antes := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	despues := antes.Add(24 * time.Hour)
+
+	tests := []struct {
+		name   string
+		cached *Result
+		merge  Result
+		want   Result
+	}{
+		{
+			name:  "sin estado se guarda",
+			merge: Result{Estado: Vigente, Consultado: antes},
+			want:  Result{Estado: Vigente, Consultado: antes},
+		},
+		{
+			name:   "más reciente reemplaza",
+			cached: &Result{Estado: Vigente, Consultado: antes},
+			merge:  Result{Estado: NoEncontrado, Consultado: despues},
+			want:   Result{Estado: NoEncontrado, Consultado: despues},
+		},
+		{
+			name:   "más antiguo no reemplaza",
+			cached: &Result{Estado: Vigente, Consultado: despues},
+			merge:  Result{Estado: NoEncontrado, Consultado: antes},
+			want:   Result{Estado: Vigente, Consultado: despues},
+		},
+		{
+			name:   "cancelación antigua reemplaza",
+			cached: &Result{Estado: Vigente, Consultado: despues},
+			merge:  Result{Estado: Cancelado, Consultado: antes, FechaCancelacion: "2022-12-31"},
+			want:   Result{Estado: Cancelado, Consultado: antes, FechaCancelacion: "2022-12-31"},
+		},
+		{
+			name:   "cancelada no se reemplaza",
+			cached: &Result{Estado: Cancelado, Consultado: antes},
+			merge:  Result{Estado: Vigente, Consultado: despues},
+			want:   Result{Estado: Cancelado, Consultado: antes},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := &Cache{items: map[string]Result{}}
+			if tt.cached != nil {
+				cache.Put(testUUID, *tt.cached)
+			}
+
+			//El UUID en minúsculas es la misma factura
+			cache.Merge("6e3a4b2c-1d5f-4a7b-9c8d-0e1f2a3b4c5d", tt.merge)
+
+			if got, _ := cache.Get(testUUID); got != tt.want {
+				t.Errorf("estado = %+v, se esperaba %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacheSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "estatus", "estados.json")
+	r := Result{Estado: Cancelado, Consultado: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	cache, err := OpenCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Put(testUUID, r)
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := OpenCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := reopened.Get(testUUID); !ok || !got.Consultado.Equal(r.Consultado) || got.Estado != r.Estado {
+		t.Errorf("estado guardado = %+v, se esperaba %+v", got, r)
+	}
+}
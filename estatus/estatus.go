@@ -0,0 +1,100 @@
+package estatus
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+)
+
+// Estado es el estado del comprobante ante el SAT
+type Estado int
+
+const (
+	// Desconocido no se ha consultado o la consulta falló
+	Desconocido Estado = iota
+	Vigente
+	Cancelado
+	// NoEncontrado el servicio no tiene el comprobante
+	NoEncontrado
+)
+
+// Estados son los estados que regresa una consulta, en orden
+var Estados = []Estado{Vigente, Cancelado, NoEncontrado}
+
+func (e Estado) String() string {
+	switch e {
+	case Vigente:
+		return "Vigente"
+	case Cancelado:
+		return "Cancelado"
+	case NoEncontrado:
+		return "No encontrado"
+	}
+	return "Desconocido"
+}
+
+// ParseEstado lee el estado como lo escribe el SAT, sin importar mayúsculas
+func ParseEstado(value string) (Estado, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "vigente":
+		return Vigente, nil
+	case "cancelado":
+		return Cancelado, nil
+	case "no encontrado", "noencontrado":
+		return NoEncontrado, nil
+	case "", "desconocido":
+		return Desconocido, nil
+	}
+	return Desconocido, fmt.Errorf("estado desconocido: %q", value)
+}
+
+func (e Estado) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+func (e *Estado) UnmarshalText(text []byte) error {
+	estado, err := ParseEstado(string(text))
+	if err != nil {
+		return err
+	}
+	*e = estado
+	return nil
+}
+
+// Consulta son los datos con que el SAT identifica un comprobante
+type Consulta struct {
+	UUID     string
+	Emisor   string
+	Receptor string
+	Total    float64
+}
+
+// ConsultaDe arma la consulta del comprobante
+func ConsultaDe(c complemento.CFDI) Consulta {
+	return Consulta{
+		UUID:     normalizeUUID(c.Complemento.TimbreFiscalDigital.UUID),
+		Emisor:   c.Emisor.RFC,
+		Receptor: c.Receptor.RFC,
+		Total:    c.Total,
+	}
+}
+
+// Provider consulta el estado de un comprobante, lo puede implementar el
+// servicio de consulta del SAT o cualquier otra fuente
+type Provider interface {
+	Consultar(q Consulta) (Estado, error)
+}
+
+// Result es el estado de un comprobante y cuándo se consultó
+type Result struct {
+	Estado     Estado    `json:"estado"`
+	Consultado time.Time `json:"consultado"`
+	// FechaCancelacion solo la trae la metadata del SAT
+	FechaCancelacion string `json:"fecha_cancelacion,omitempty"`
+}
+
+func normalizeUUID(uuid string) string {
+	return strings.ToUpper(strings.TrimSpace(uuid))
+}
@@ -0,0 +1,59 @@
+package estatus
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// FileProvider lee los estados de un archivo de texto con una factura por
+// línea, UUID y estado separados por |, coma, punto y coma o tabulador:
+//
+//	6F8E2A4C-0D4B-4D7A-9E0B-2C1A3B4C5D6E|Cancelado
+//
+// Sirve para pruebas o para estados que se consultaron por otro medio
+type FileProvider struct {
+	estados map[string]Estado
+}
+
+func OpenFileProvider(path string) (*FileProvider, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	p := &FileProvider{estados: map[string]Estado{}}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimPrefix(strings.TrimSpace(scanner.Text()), "\ufeff")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r == '|' || r == ',' || r == ';' || r == '\t'
+		})
+		if len(fields) < 2 {
+			continue
+		}
+
+		//Los encabezados y las líneas con otro texto no son estados
+		estado, err := ParseEstado(fields[len(fields)-1])
+		if err != nil {
+			continue
+		}
+		p.estados[normalizeUUID(fields[0])] = estado
+	}
+
+	return p, scanner.Err()
+}
+
+// Consultar regresa no encontrado si el archivo no tiene la factura
+func (p *FileProvider) Consultar(q Consulta) (Estado, error) {
+	if estado, ok := p.estados[q.UUID]; ok {
+		return estado, nil
+	}
+	return NoEncontrado, nil
+}
@@ -0,0 +1,73 @@
+package estatus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPProvider consulta un servicio propio que recibe los mismos datos que
+// la consulta del SAT (id, re, rr y tt) y responde el estado en texto o en
+// JSON como {"estado": "Vigente"}
+type HTTPProvider struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewHTTPProvider(rawURL string) (*HTTPProvider, error) {
+	if _, err := url.Parse(rawURL); err != nil {
+		return nil, err
+	}
+	return &HTTPProvider{
+		URL:    rawURL,
+		Client: &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+func (p *HTTPProvider) Consultar(q Consulta) (Estado, error) {
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		return Desconocido, err
+	}
+
+	params := u.Query()
+	params.Set("id", q.UUID)
+	params.Set("re", q.Emisor)
+	params.Set("rr", q.Receptor)
+	params.Set("tt", strconv.FormatFloat(q.Total, 'f', 2, 64))
+	u.RawQuery = params.Encode()
+
+	resp, err := p.Client.Get(u.String())
+	if err != nil {
+		return Desconocido, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return NoEncontrado, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Desconocido, fmt.Errorf("consulta de %s: %s", q.UUID, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return Desconocido, err
+	}
+
+	var respuesta struct {
+		Estado string `json:"estado"`
+	}
+	if strings.HasPrefix(strings.TrimSpace(string(body)), "{") {
+		if err := json.Unmarshal(body, &respuesta); err != nil {
+			return Desconocido, err
+		}
+		return ParseEstado(respuesta.Estado)
+	}
+	return ParseEstado(string(body))
+}
@@ -0,0 +1,495 @@
+package impresa
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/dannywolfmx/cfdi-xls/letra"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/leekchan/accounting"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+var ac = accounting.Accounting{Symbol: "$", Precision: 2}
+
+// Medidas de la página carta en milímetros
+const (
+	margen    = 12.0
+	anchoUtil = 215.9 - 2*margen
+	renglon   = 4.5
+)
+
+var tiposComprobante = map[string]string{
+	"I": "Ingreso",
+	"E": "Egreso",
+	"T": "Traslado",
+	"N": "Nómina",
+	"P": "Pago",
+}
+
+var impuestos = map[string]string{
+	"001": "ISR",
+	"002": "IVA",
+	"003": "IEPS",
+}
+
+// Columnas de la tabla de conceptos, la descripción ocupa lo que sobra
+var columnasConceptos = []struct {
+	titulo string
+	ancho  float64
+	align  string
+}{
+	{"Clave", 20, "L"},
+	{"Cantidad", 16, "R"},
+	{"Unidad", 16, "L"},
+	{"Descripción", 0, "L"},
+	{"Valor unitario", 24, "R"},
+	{"Descuento", 20, "R"},
+	{"Importe", 24, "R"},
+}
+
+// WriteFile escribe la representación impresa del comprobante en path
+func WriteFile(c complemento.CFDI, path string) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := Render(c, f); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+
+	return f.Close()
+}
+
+// Render escribe la representación impresa del comprobante en formato PDF:
+// emisor y receptor, conceptos, impuestos, totales con letra, sellos, cadena
+// original del timbre y el código QR para verificarlo en el SAT
+func Render(c complemento.CFDI, w io.Writer) error {
+	p := &pagina{Fpdf: gofpdf.New("P", "mm", "Letter", "")}
+	p.tr = p.UnicodeTranslatorFromDescriptor("")
+
+	p.SetMargins(margen, margen, margen)
+	p.SetAutoPageBreak(true, margen+6)
+	p.SetTitle(p.tr("CFDI "+c.Complemento.TimbreFiscalDigital.UUID), false)
+	p.AliasNbPages("")
+	p.SetFooterFunc(func() {
+		p.SetY(-margen - 3)
+		p.SetFont("Helvetica", "I", 7)
+		p.SetTextColor(110, 110, 110)
+		p.CellFormat(anchoUtil/2, 4, p.tr("Este documento es una representación impresa de un CFDI"), "", 0, "L", false, 0, "")
+		p.CellFormat(anchoUtil/2, 4, p.tr(fmt.Sprintf("Página %d de {nb}", p.PageNo())), "", 0, "R", false, 0, "")
+	})
+	p.AddPage()
+
+	p.encabezado(c)
+	p.receptor(c)
+	p.conceptos(c)
+	p.pago(c)
+	p.totales(c)
+	p.timbre(c)
+
+	if err := p.Error(); err != nil {
+		return err
+	}
+	return p.Output(w)
+}
+
+type pagina struct {
+	*gofpdf.Fpdf
+	tr func(string) string
+}
+
+func (p *pagina) titulo(text string) {
+	p.SetFont("Helvetica", "B", 8)
+	p.SetFillColor(230, 230, 230)
+	p.SetTextColor(0, 0, 0)
+	p.CellFormat(anchoUtil, 5, p.tr(text), "", 1, "L", true, 0, "")
+}
+
+// campo escribe una etiqueta en negritas y su valor en la misma línea
+func (p *pagina) campo(label, value string, ancho float64) {
+	p.SetFont("Helvetica", "B", 7.5)
+	lw := p.GetStringWidth(p.tr(label)) + 1.5
+	p.CellFormat(lw, renglon, p.tr(label), "", 0, "L", false, 0, "")
+	p.SetFont("Helvetica", "", 7.5)
+	p.CellFormat(ancho-lw, renglon, p.tr(value), "", 0, "L", false, 0, "")
+}
+
+func (p *pagina) encabezado(c complemento.CFDI) {
+	tfd := c.Complemento.TimbreFiscalDigital
+	anchoDatos := 78.0
+	anchoEmisor := anchoUtil - anchoDatos - 4
+	top := p.GetY()
+
+	p.SetFont("Helvetica", "B", 12)
+	p.MultiCell(anchoEmisor, 5.5, p.tr(c.Emisor.Nombre), "", "L", false)
+	p.campo("RFC:", c.Emisor.RFC, anchoEmisor)
+	p.Ln(renglon)
+	if c.Emisor.RegimenFiscal != "" {
+		p.campo("Régimen fiscal:", c.Emisor.RegimenFiscal, anchoEmisor)
+		p.Ln(renglon)
+	}
+	if c.LugarExpedicion != "" {
+		p.campo("Lugar de expedición:", c.LugarExpedicion, anchoEmisor)
+		p.Ln(renglon)
+	}
+	bottom := p.GetY()
+
+	//Recuadro con los datos del comprobante a la derecha
+	x := margen + anchoUtil - anchoDatos
+	p.SetXY(x, top)
+	tipo := tiposComprobante[c.TipoDeComprobante]
+	if tipo == "" {
+		tipo = c.TipoDeComprobante
+	}
+	p.SetFont("Helvetica", "B", 10)
+	p.SetFillColor(230, 230, 230)
+	p.CellFormat(anchoDatos, 6, p.tr("CFDI de "+strings.ToLower(tipo)+" "+c.Version), "1", 2, "C", true, 0, "")
+
+	datos := [][2]string{
+		{"Serie y folio:", strings.TrimSpace(c.Serie + " " + c.Folio)},
+		{"Fecha:", c.Fecha},
+		{"Folio fiscal:", tfd.UUID},
+		{"Certificado emisor:", c.NoCertificado},
+	}
+	for _, d := range datos {
+		p.SetX(x)
+		p.campo(d[0], d[1], anchoDatos)
+		p.Ln(renglon)
+	}
+	p.Rect(x, top+6, anchoDatos, p.GetY()-top-6, "D")
+
+	if p.GetY() < bottom {
+		p.SetY(bottom)
+	}
+	p.Ln(3)
+}
+
+func (p *pagina) receptor(c complemento.CFDI) {
+	p.titulo("Receptor")
+	mitad := anchoUtil / 2
+
+	p.SetFont("Helvetica", "B", 8)
+	p.MultiCell(anchoUtil, renglon, p.tr(c.Receptor.Nombre), "", "L", false)
+	p.campo("RFC:", c.Receptor.RFC, mitad)
+	p.campo("Uso CFDI:", c.Receptor.UsoCFDI, mitad)
+	p.Ln(renglon)
+	if c.Receptor.RegimenFiscal != "" || c.Receptor.DomicilioFiscal != "" {
+		p.campo("Régimen fiscal:", c.Receptor.RegimenFiscal, mitad)
+		p.campo("Domicilio fiscal:", c.Receptor.DomicilioFiscal, mitad)
+		p.Ln(renglon)
+	}
+	p.Ln(3)
+}
+
+// anchoDescripcion es lo que queda para la descripción después de las demás columnas
+func anchoDescripcion() float64 {
+	ancho := anchoUtil
+	for _, col := range columnasConceptos {
+		ancho -= col.ancho
+	}
+	return ancho
+}
+
+func (p *pagina) encabezadoConceptos() {
+	p.SetFont("Helvetica", "B", 7.5)
+	p.SetFillColor(230, 230, 230)
+	for _, col := range columnasConceptos {
+		ancho := col.ancho
+		if ancho == 0 {
+			ancho = anchoDescripcion()
+		}
+		p.CellFormat(ancho, 5, p.tr(col.titulo), "B", 0, col.align, true, 0, "")
+	}
+	p.Ln(5)
+}
+
+func (p *pagina) conceptos(c complemento.CFDI) {
+	p.titulo("Conceptos")
+	p.encabezadoConceptos()
+
+	_, alto := p.GetPageSize()
+	_, _, _, inferior := p.GetMargins()
+	p.SetFont("Helvetica", "", 7.5)
+
+	for _, concepto := range c.Conceptos {
+		descripcion := p.SplitLines([]byte(p.tr(concepto.Descripcion)), anchoDescripcion()-2)
+		h := float64(max1(len(descripcion))) * 3.6
+
+		//La fila no se parte entre páginas, el encabezado se repite
+		if p.GetY()+h > alto-inferior {
+			p.AddPage()
+			p.encabezadoConceptos()
+			p.SetFont("Helvetica", "", 7.5)
+		}
+
+		unidad := concepto.ClaveUnidad
+		if concepto.Unidad != "" {
+			unidad += " " + concepto.Unidad
+		}
+		descuento := ""
+		if concepto.Descuento > 0 {
+			descuento = ac.FormatMoney(concepto.Descuento)
+		}
+
+		valores := []string{
+			concepto.ClaveProdServ,
+			strconv.FormatFloat(concepto.Cantidad, 'f', -1, 64),
+			unidad,
+			"",
+			ac.FormatMoney(concepto.ValorUnitario),
+			descuento,
+			ac.FormatMoney(concepto.Importe),
+		}
+
+		y := p.GetY()
+		for i, col := range columnasConceptos {
+			if col.ancho == 0 {
+				x := p.GetX()
+				for j, line := range descripcion {
+					p.SetXY(x, y+float64(j)*3.6)
+					p.CellFormat(anchoDescripcion(), 3.6, string(line), "", 0, "L", false, 0, "")
+				}
+				p.SetXY(x+anchoDescripcion(), y)
+				continue
+			}
+			p.CellFormat(col.ancho, 3.6, p.tr(valores[i]), "", 0, col.align, false, 0, "")
+		}
+		p.SetY(y + h + 0.8)
+		p.SetDrawColor(220, 220, 220)
+		p.Line(margen, p.GetY(), margen+anchoUtil, p.GetY())
+		p.SetDrawColor(0, 0, 0)
+		p.Ln(0.8)
+	}
+	p.Ln(2)
+}
+
+func (p *pagina) pago(c complemento.CFDI) {
+	if c.TipoDeComprobante == "P" {
+		p.pagos(c)
+		return
+	}
+
+	tercio := anchoUtil / 3
+	p.campo("Método de pago:", c.MetodoPago, tercio)
+	p.campo("Forma de pago:", c.FormaPago, tercio)
+	moneda := c.Moneda
+	if c.TipoCambio != "" && c.TipoCambio != "1" {
+		moneda += " (TC " + c.TipoCambio + ")"
+	}
+	p.campo("Moneda:", moneda, tercio)
+	p.Ln(renglon)
+	if c.CondicionesDePago != "" {
+		p.campo("Condiciones de pago:", c.CondicionesDePago, anchoUtil)
+		p.Ln(renglon)
+	}
+	p.Ln(2)
+}
+
+// pagos lista los pagos del complemento y los documentos que liquidan
+func (p *pagina) pagos(c complemento.CFDI) {
+	p.titulo("Complemento de pago")
+	for _, pago := range c.Complemento.Pagos.Pago {
+		cuarto := anchoUtil / 4
+		p.campo("Fecha de pago:", pago.FechaPago, cuarto)
+		p.campo("Forma de pago:", pago.FormaDePagoP, cuarto)
+		p.campo("Moneda:", pago.MonedaP, cuarto)
+		p.campo("Monto:", pago.Monto, cuarto)
+		p.Ln(renglon)
+
+		p.SetFont("Helvetica", "", 7)
+		for _, docto := range pago.DoctoRelacionado {
+			text := fmt.Sprintf("%s  %s  saldo anterior %s  pagado %s  insoluto %s",
+				docto.IDDocumento, strings.TrimSpace(docto.Serie+" "+docto.Folio),
+				docto.ImpSaldoAnt, ac.FormatMoney(docto.ImpPagado), docto.ImpSaldoInsoluto)
+			p.CellFormat(anchoUtil, 3.6, p.tr(text), "", 1, "L", false, 0, "")
+		}
+		p.Ln(1.5)
+	}
+	p.Ln(1)
+}
+
+func (p *pagina) totales(c complemento.CFDI) {
+	anchoTotales := 70.0
+	anchoLetra := anchoUtil - anchoTotales - 4
+	top := p.GetY()
+
+	filas := [][2]string{{"SubTotal", ac.FormatMoney(c.SubTotal)}}
+	if c.Descuento > 0 {
+		filas = append(filas, [2]string{"Descuento", ac.FormatMoney(c.Descuento)})
+	}
+	for _, t := range c.Impuestos.Traslados {
+		filas = append(filas, [2]string{fmt.Sprintf("%s trasladado %s", nombreImpuesto(t.Impuesto), tasa(t.TasaOCuota)), ac.FormatMoney(t.Importe)})
+	}
+	for _, r := range c.Impuestos.Retenciones {
+		filas = append(filas, [2]string{nombreImpuesto(r.Impuesto) + " retenido", ac.FormatMoney(r.Importe)})
+	}
+	locales := c.Complemento.ImpuestosLocales
+	if locales.TotalTraslados > 0 {
+		filas = append(filas, [2]string{"Impuestos locales trasladados", ac.FormatMoney(locales.TotalTraslados)})
+	}
+	if locales.TotalRetenciones > 0 {
+		filas = append(filas, [2]string{"Impuestos locales retenidos", ac.FormatMoney(locales.TotalRetenciones)})
+	}
+
+	//Importe con letra a la izquierda
+	p.SetFont("Helvetica", "B", 7.5)
+	p.CellFormat(anchoLetra, renglon, p.tr("Importe con letra:"), "", 2, "L", false, 0, "")
+	p.SetFont("Helvetica", "", 7.5)
+	p.MultiCell(anchoLetra, renglon, p.tr(letra.Importe(c.Total, c.Moneda)), "", "L", false)
+	bottom := p.GetY()
+
+	x := margen + anchoUtil - anchoTotales
+	p.SetY(top)
+	for _, fila := range filas {
+		p.SetX(x)
+		p.SetFont("Helvetica", "", 7.5)
+		p.CellFormat(anchoTotales-28, renglon, p.tr(fila[0]), "", 0, "R", false, 0, "")
+		p.CellFormat(28, renglon, fila[1], "", 1, "R", false, 0, "")
+	}
+	p.SetX(x)
+	p.SetFont("Helvetica", "B", 9)
+	p.CellFormat(anchoTotales-28, 6, "Total", "T", 0, "R", false, 0, "")
+	p.CellFormat(28, 6, ac.FormatMoney(c.Total), "T", 1, "R", false, 0, "")
+
+	if p.GetY() < bottom {
+		p.SetY(bottom)
+	}
+	p.Ln(4)
+}
+
+// timbre escribe el código QR, los sellos y la cadena original del timbre
+func (p *pagina) timbre(c complemento.CFDI) {
+	tfd := c.Complemento.TimbreFiscalDigital
+	lado := 36.0
+	ancho := anchoUtil - lado - 4
+
+	//El bloque de sellos no se parte, si no cabe empieza en otra página
+	_, alto := p.GetPageSize()
+	_, _, _, inferior := p.GetMargins()
+	if p.GetY()+lado+38 > alto-inferior {
+		p.AddPage()
+	}
+
+	p.titulo("Timbre fiscal digital")
+	top := p.GetY() + 1
+
+	png, err := qrcode.Encode(c.VerificacionURL(), qrcode.Medium, 512)
+	if err != nil {
+		p.SetError(err)
+		return
+	}
+	p.RegisterImageOptionsReader("qr", gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+	p.ImageOptions("qr", margen, top, lado, lado, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, c.VerificacionURL())
+
+	x := margen + lado + 4
+	p.SetXY(x, top)
+	p.campo("Fecha de timbrado:", tfd.FechaTimbrado, ancho/2)
+	p.campo("Certificado SAT:", tfd.NoCertificadoSAT, ancho/2)
+	p.Ln(renglon)
+	p.SetX(x)
+	p.campo("RFC del proveedor de certificación:", tfd.RfcProvCertif, ancho)
+	p.Ln(renglon + 1)
+
+	bloques := [][2]string{
+		{"Sello digital del CFDI:", c.Sello},
+		{"Sello digital del SAT:", tfd.SelloSAT},
+		{"Cadena original del complemento de certificación digital del SAT:", tfd.CadenaOriginal()},
+	}
+	for _, b := range bloques {
+		p.SetX(x)
+		p.SetFont("Helvetica", "B", 7)
+		p.CellFormat(ancho, 3.5, p.tr(b[0]), "", 2, "L", false, 0, "")
+		p.SetFont("Courier", "", 6)
+		p.MultiCell(ancho, 2.8, p.tr(strings.Join(strings.Fields(b[1]), "")), "", "L", false)
+		p.Ln(1)
+	}
+
+	if p.GetY() < top+lado {
+		p.SetY(top + lado)
+	}
+}
+
+func nombreImpuesto(clave string) string {
+	if nombre, ok := impuestos[clave]; ok {
+		return nombre
+	}
+	return clave
+}
+
+// tasa escribe la tasa como porcentaje, 0.160000 es 16%
+func tasa(value string) string {
+	t, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+	return strconv.FormatFloat(math.Round(t*1e6)/1e4, 'f', -1, 64) + "%"
+}
+
+func max1(n int) int {
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+// FileName es el nombre del PDF de la factura, el de su XML con extensión
+// .pdf, el UUID si no se conoce el archivo o cfdi.pdf si tampoco hay UUID
+func FileName(c complemento.CFDI) string {
+	name := ""
+	if c.Path != "" {
+		base := filepath.Base(c.Path)
+		name = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	if name == "" {
+		name = c.Complemento.TimbreFiscalDigital.UUID
+	}
+	if name == "" {
+		name = "cfdi"
+	}
+	return name + ".pdf"
+}
+
+// Nombres reparte los nombres de los PDF que van a una misma carpeta. Si dos
+// XML de carpetas distintas se llaman igual el siguiente usa su UUID y, si
+// tampoco está libre, un número al final para no sobrescribir el primero
+type Nombres map[string]bool
+
+// FileName es el nombre libre del PDF de la factura y lo marca como usado
+func (n Nombres) FileName(c complemento.CFDI) string {
+	name := FileName(c)
+	if n.usado(name) {
+		if uuid := c.Complemento.TimbreFiscalDigital.UUID; uuid != "" {
+			name = uuid + ".pdf"
+		}
+	}
+
+	base := strings.TrimSuffix(name, ".pdf")
+	for i := 2; n.usado(name); i++ {
+		name = fmt.Sprintf("%s-%d.pdf", base, i)
+	}
+
+	//Windows y macOS no distinguen mayúsculas en los nombres de archivo
+	n[strings.ToLower(name)] = true
+	return name
+}
+
+func (n Nombres) usado(name string) bool {
+	return n[strings.ToLower(name)]
+}
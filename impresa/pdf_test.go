@@ -0,0 +1,97 @@
+package impresa
+
+import (
+	"testing"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+)
+
+func testCFDI(path, uuid string) complemento.CFDI {
+	var c complemento.CFDI
+	c.Path = path
+	c.Complemento.TimbreFiscalDigital.UUID = uuid
+	return c
+}
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		cfdi complemento.CFDI
+		want string
+	}{
+		{name: "nombre del XML", cfdi: testCFDI("/facturas/enero/A-1.xml", "AAAA-1"), want: "A-1.pdf"},
+		{name: "sin archivo", cfdi: testCFDI("", "AAAA-1"), want: "AAAA-1.pdf"},
+		{name: "XML sin nombre", cfdi: testCFDI("/facturas/.xml", "AAAA-1"), want: "AAAA-1.pdf"},
+		{name: "sin archivo ni UUID", cfdi: testCFDI("", ""), want: "cfdi.pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FileName(tt.cfdi); got != tt.want {
+				t.Errorf("FileName = %q, se esperaba %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNombresFileName(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfdis []complemento.CFDI
+		want  []string
+	}{
+		{
+			name: "nombres distintos",
+			cfdis: []complemento.CFDI{
+				testCFDI("/enero/A-1.xml", "AAAA-1"),
+				testCFDI("/enero/A-2.xml", "AAAA-2"),
+			},
+			want: []string{"A-1.pdf", "A-2.pdf"},
+		},
+		{
+			name: "mismo nombre usa el UUID",
+			cfdis: []complemento.CFDI{
+				testCFDI("/enero/factura.xml", "AAAA-1"),
+				testCFDI("/febrero/factura.xml", "AAAA-2"),
+			},
+			want: []string{"factura.pdf", "AAAA-2.pdf"},
+		},
+		{
+			name: "UUID ocupado agrega un número",
+			cfdis: []complemento.CFDI{
+				testCFDI("/enero/factura.xml", "AAAA-1"),
+				testCFDI("/febrero/factura.xml", "AAAA-1"),
+				testCFDI("/marzo/factura.xml", "AAAA-1"),
+			},
+			want: []string{"factura.pdf", "AAAA-1.pdf", "AAAA-1-2.pdf"},
+		},
+		{
+			name: "sin mayúsculas",
+			cfdis: []complemento.CFDI{
+				testCFDI("/enero/Factura.xml", "AAAA-1"),
+				testCFDI("/febrero/FACTURA.XML", "aaaa-1"),
+				testCFDI("/marzo/factura.xml", ""),
+			},
+			want: []string{"Factura.pdf", "aaaa-1.pdf", "factura-2.pdf"},
+		},
+		{
+			name: "sin archivo ni UUID",
+			cfdis: []complemento.CFDI{
+				testCFDI("", ""),
+				testCFDI("", ""),
+			},
+			want: []string{"cfdi.pdf", "cfdi-2.pdf"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nombres := Nombres{}
+			for i, c := range tt.cfdis {
+				if got := nombres.FileName(c); got != tt.want[i] {
+					t.Errorf("factura %d: FileName = %q, se esperaba %q", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package ledger
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Campos de la contabilidad que se pueden mapear a una columna
+const (
+	FieldUUID    = "uuid"
+	FieldSerie   = "serie"
+	FieldFolio   = "folio"
+	FieldRFC     = "rfc"
+	FieldImporte = "importe"
+)
+
+var fields = []string{FieldUUID, FieldSerie, FieldFolio, FieldRFC, FieldImporte}
+
+// Encabezados que se buscan cuando no se indica la columna de un campo,
+// se comparan sin acentos, espacios ni mayúsculas
+var defaultHeaders = map[string][]string{
+	FieldUUID:    {"uuid", "foliofiscal"},
+	FieldSerie:   {"serie"},
+	FieldFolio:   {"folio"},
+	FieldRFC:     {"rfc", "rfcemisor", "rfcproveedor"},
+	FieldImporte: {"importe", "total", "monto"},
+}
+
+// Cuántas filas se revisan buscando los encabezados, los reportes del ERP
+// suelen traer un título antes de la tabla
+const maxHeaderRows = 20
+
+// Mapping indica en qué columna está cada campo, por el título del
+// encabezado o por la letra de la columna, por ejemplo uuid=Folio fiscal,importe=F
+type Mapping struct {
+	Columns map[string]string
+	// Sheet es la hoja de excel que se lee, si está vacía se usa la primera
+	Sheet string
+}
+
+// ParseMapping lee campo=columna separados por comas
+func ParseMapping(value string) (Mapping, error) {
+	m := Mapping{Columns: map[string]string{}}
+
+	for _, part := range strings.Split(value, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		field, column, ok := strings.Cut(part, "=")
+		field = strings.ToLower(strings.TrimSpace(field))
+		column = strings.TrimSpace(column)
+		if !ok || column == "" {
+			return m, fmt.Errorf("columna inválida %q, usa campo=columna", part)
+		}
+
+		if field == "hoja" {
+			m.Sheet = column
+			continue
+		}
+		if !containsString(fields, field) {
+			return m, fmt.Errorf("campo %q no existe, usa %s u hoja", field, strings.Join(fields, ", "))
+		}
+		m.Columns[field] = column
+	}
+
+	return m, nil
+}
+
+// Entry es un registro de la contabilidad
+type Entry struct {
+	// Row es el número de fila en el archivo, para buscarla en el ERP
+	Row     int
+	UUID    string
+	Serie   string
+	Folio   string
+	RFC     string
+	Importe float64
+}
+
+// Read lee los registros de un libro de excel o de un CSV, las filas sin
+// UUID ni folio se ignoran
+func Read(path string, m Mapping) ([]Entry, error) {
+	var rows [][]string
+	var err error
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xlsx", ".xlsm":
+		rows, err = readXLSX(path, m.Sheet)
+	case ".csv", ".tsv", ".txt":
+		rows, err = readCSV(path)
+	default:
+		return nil, fmt.Errorf("formato de contabilidad no soportado %q, usa .xlsx o .csv", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	headerRow, columns, err := findHeader(rows, m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	entries := make([]Entry, 0, len(rows)-headerRow)
+	for i := headerRow + 1; i < len(rows); i++ {
+		row := rows[i]
+		e := Entry{
+			Row:   i + 1,
+			UUID:  strings.ToUpper(cellAt(row, columns, FieldUUID)),
+			Serie: cellAt(row, columns, FieldSerie),
+			Folio: cellAt(row, columns, FieldFolio),
+			RFC:   strings.ToUpper(cellAt(row, columns, FieldRFC)),
+		}
+		if e.UUID == "" && e.Serie+e.Folio == "" {
+			continue
+		}
+
+		if e.Importe, err = parseAmount(cellAt(row, columns, FieldImporte)); err != nil {
+			return nil, fmt.Errorf("%s fila %d: %w", path, e.Row, err)
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+func readXLSX(path, sheet string) ([][]string, error) {
+	file, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if sheet == "" {
+		sheet = file.GetSheetList()[0]
+	}
+
+	//Sin el formato de la celda los importes llegan como 1160.5 y no como $1,160.50
+	return file.GetRows(sheet, excelize.Options{RawCellValue: true})
+}
+
+// readCSV lee el archivo separado por comas, punto y coma o tabuladores según
+// lo que aparezca más en la primera línea
+func readCSV(path string) ([][]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimPrefix(string(content), "\xEF\xBB\xBF")
+
+	first, _, _ := strings.Cut(text, "\n")
+	delimiter := ','
+	for _, candidate := range []rune{';', '\t'} {
+		if strings.Count(first, string(candidate)) > strings.Count(first, string(delimiter)) {
+			delimiter = candidate
+		}
+	}
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	return reader.ReadAll()
+}
+
+// findHeader busca la primera fila que tenga las columnas necesarias y
+// regresa el índice de la columna de cada campo
+func findHeader(rows [][]string, m Mapping) (int, map[string]int, error) {
+	for i := 0; i < len(rows) && i < maxHeaderRows; i++ {
+		columns := headerColumns(rows[i], m)
+		_, hasImporte := columns[FieldImporte]
+		_, hasUUID := columns[FieldUUID]
+		_, hasFolio := columns[FieldFolio]
+		if hasImporte && (hasUUID || hasFolio) {
+			return i, columns, nil
+		}
+	}
+	return 0, nil, errors.New("no se encontraron las columnas de importe y uuid o folio, indícalas con -columnas")
+}
+
+func headerColumns(row []string, m Mapping) map[string]int {
+	titles := make(map[string]int, len(row))
+	for i, title := range row {
+		if key := normalizeHeader(title); key != "" {
+			if _, ok := titles[key]; !ok {
+				titles[key] = i
+			}
+		}
+	}
+
+	columns := make(map[string]int)
+	for _, field := range fields {
+		if column, ok := m.Columns[field]; ok {
+			if i, ok := titles[normalizeHeader(column)]; ok {
+				columns[field] = i
+			} else if n, err := excelize.ColumnNameToNumber(column); err == nil {
+				columns[field] = n - 1
+			}
+			continue
+		}
+
+		for _, header := range defaultHeaders[field] {
+			if i, ok := titles[header]; ok {
+				columns[field] = i
+				break
+			}
+		}
+	}
+	return columns
+}
+
+var accents = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n")
+
+// normalizeHeader quita acentos, espacios y signos del título, "Folio Fiscal" -> foliofiscal
+func normalizeHeader(title string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, accents.Replace(strings.ToLower(title)))
+}
+
+func cellAt(row []string, columns map[string]int, field string) string {
+	i, ok := columns[field]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// parseAmount acepta importes con signo de pesos y separadores de miles,
+// también con coma decimal como 1.160,50
+func parseAmount(value string) (float64, error) {
+	value = strings.NewReplacer("$", "", " ", "", "MXN", "").Replace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	if strings.LastIndex(value, ",") > strings.LastIndex(value, ".") && len(value)-strings.LastIndex(value, ",") <= 3 {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.Replace(value, ",", ".", 1)
+	} else {
+		value = strings.ReplaceAll(value, ",", "")
+	}
+
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("importe inválido %q", value)
+	}
+	return amount, nil
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
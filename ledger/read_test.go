@@ -0,0 +1,99 @@
+package ledger
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+	}{
+		{"$1,160.50", 1160.50},
+		{"1.160,50", 1160.50},
+		{"1,160", 1160},
+		{"1160.5", 1160.5},
+		{"1,5", 1.5},
+		{"-$ 25.00 MXN", -25},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		got, err := parseAmount(tt.value)
+		if err != nil {
+			t.Errorf("parseAmount(%q): %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseAmount(%q) = %v, se esperaba %v", tt.value, got, tt.want)
+		}
+	}
+
+	if _, err := parseAmount("mil"); err == nil {
+		t.Error("parseAmount(\"mil\") debería regresar error")
+	}
+}
+
+func TestFindHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    [][]string
+		mapping Mapping
+		row     int
+		columns map[string]int
+	}{
+		{
+			name: "título antes de los encabezados",
+			rows: [][]string{
+				{"Reporte de compras enero"},
+				{},
+				{"Folio Fiscal", "RFC Proveedor", "Serie", "Folio", "Total"},
+				{"ABC", "AAA010101AAA", "A", "1", "100"},
+			},
+			mapping: Mapping{Columns: map[string]string{}},
+			row:     2,
+			columns: map[string]int{FieldUUID: 0, FieldRFC: 1, FieldSerie: 2, FieldFolio: 3, FieldImporte: 4},
+		},
+		{
+			name: "importe por letra de columna",
+			rows: [][]string{
+				{"UUID", "Proveedor", "Subtotal", "IVA", "Retención", "Neto"},
+			},
+			mapping: Mapping{Columns: map[string]string{FieldImporte: "F"}},
+			row:     0,
+			columns: map[string]int{FieldUUID: 0, FieldImporte: 5},
+		},
+		{
+			name: "campos por nombre de encabezado",
+			rows: [][]string{
+				{"Póliza", "Documento", "Cargo", "Identificador SAT"},
+			},
+			mapping: Mapping{Columns: map[string]string{FieldUUID: "identificador sat", FieldFolio: "Documento", FieldImporte: "Cargo"}},
+			row:     0,
+			columns: map[string]int{FieldUUID: 3, FieldFolio: 1, FieldImporte: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row, columns, err := findHeader(tt.rows, tt.mapping)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if row != tt.row {
+				t.Errorf("fila = %d, se esperaba %d", row, tt.row)
+			}
+			if !reflect.DeepEqual(columns, tt.columns) {
+				t.Errorf("columnas = %v, se esperaba %v", columns, tt.columns)
+			}
+		})
+	}
+}
+
+func TestFindHeaderSinColumnas(t *testing.T) {
+	rows := [][]string{{"Proveedor", "Total"}}
+	if _, _, err := findHeader(rows, Mapping{}); err == nil {
+		t.Error("sin uuid ni folio debería regresar error")
+	}
+}
@@ -0,0 +1,155 @@
+package ledger
+
+import (
+	"math"
+	"strings"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+)
+
+// Status es el resultado de conciliar un registro o una factura
+type Status int
+
+const (
+	// Conciliada el registro y el XML coinciden en importe
+	Conciliada Status = iota
+	// DiferenciaImporte el registro tiene su XML pero el importe no coincide
+	DiferenciaImporte
+	// SinRegistro el XML no está en la contabilidad
+	SinRegistro
+	// SinXML el registro de la contabilidad no tiene XML
+	SinXML
+)
+
+// Statuses en el orden en que se muestran
+var Statuses = []Status{Conciliada, DiferenciaImporte, SinRegistro, SinXML}
+
+func (s Status) String() string {
+	switch s {
+	case Conciliada:
+		return "Conciliada"
+	case DiferenciaImporte:
+		return "Diferencia de importe"
+	case SinRegistro:
+		return "Sin registro en contabilidad"
+	case SinXML:
+		return "Sin XML"
+	}
+	return "Desconocido"
+}
+
+// Match une un registro de la contabilidad con su factura, en SinRegistro no
+// hay Entry y en SinXML no hay CFDI
+type Match struct {
+	Status Status
+	Entry  *Entry
+	CFDI   *complemento.CFDI
+	// Diferencia es el importe de la contabilidad menos el total del XML en pesos
+	Diferencia float64
+	Nota       string
+}
+
+// Result es la conciliación completa, primero los registros en el orden del
+// archivo y al final las facturas que no están en la contabilidad
+type Result struct {
+	Matches []Match
+}
+
+// Count regresa cuántos registros o facturas quedaron con el estado
+func (r Result) Count(status Status) int {
+	count := 0
+	for _, m := range r.Matches {
+		if m.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Reconcile busca cada registro por UUID o, si no lo tiene, por RFC del
+// emisor con serie y folio. Los importes que difieren más que tolerance
+// quedan como DiferenciaImporte
+func Reconcile(entries []Entry, cfdis []complemento.CFDI, tolerance float64) Result {
+	byUUID := make(map[string]int, len(cfdis))
+	byFolio := make(map[string][]int)
+	for i, c := range cfdis {
+		byUUID[strings.ToUpper(c.Complemento.TimbreFiscalDigital.UUID)] = i
+		key := folioKey(c.Serie, c.Folio)
+		byFolio[key] = append(byFolio[key], i)
+	}
+
+	used := make(map[int]bool, len(entries))
+	matches := make([]Match, 0, len(entries)+len(cfdis))
+
+	for i := range entries {
+		e := &entries[i]
+
+		index, nota := findCFDI(e, cfdis, byUUID, byFolio)
+		if index >= 0 && used[index] {
+			index, nota = -1, "El XML ya se concilió con otro registro"
+		}
+		if index < 0 {
+			matches = append(matches, Match{Status: SinXML, Entry: e, Diferencia: e.Importe, Nota: nota})
+			continue
+		}
+		used[index] = true
+
+		//La contabilidad está en pesos, las facturas en otra moneda se convierten
+		m := Match{Status: Conciliada, Entry: e, CFDI: &cfdis[index], Diferencia: e.Importe - totalMXN(cfdis[index])}
+		if math.Abs(m.Diferencia) > tolerance {
+			m.Status = DiferenciaImporte
+		}
+		matches = append(matches, m)
+	}
+
+	for i := range cfdis {
+		//Los complementos de pago no se registran como compras, su total es cero
+		if used[i] || cfdis[i].TipoDeComprobante == "P" {
+			continue
+		}
+		matches = append(matches, Match{Status: SinRegistro, CFDI: &cfdis[i], Diferencia: -totalMXN(cfdis[i])})
+	}
+
+	return Result{Matches: matches}
+}
+
+// totalMXN es el total de la factura convertido a pesos con su tipo de cambio
+func totalMXN(c complemento.CFDI) float64 {
+	return c.Total * c.TipoDeCambio()
+}
+
+// findCFDI regresa el índice de la factura del registro o -1 con el motivo
+func findCFDI(e *Entry, cfdis []complemento.CFDI, byUUID map[string]int, byFolio map[string][]int) (int, string) {
+	if e.UUID != "" {
+		if index, ok := byUUID[e.UUID]; ok {
+			return index, ""
+		}
+		return -1, "No hay XML con ese UUID"
+	}
+
+	candidates := make([]int, 0)
+	for _, index := range byFolio[folioKey(e.Serie, e.Folio)] {
+		if e.RFC == "" || strings.EqualFold(cfdis[index].Emisor.RFC, e.RFC) {
+			candidates = append(candidates, index)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return -1, "No hay XML con esa serie y folio"
+	case 1:
+		return candidates[0], ""
+	}
+	return -1, "Varios XML con esa serie y folio, agrega el RFC o el UUID"
+}
+
+// folioKey junta serie y folio sin separadores, así A-123 en el ERP coincide
+// con serie A y folio 123 en el XML
+func folioKey(serie, folio string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' || r == '/' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(serie+folio))
+}
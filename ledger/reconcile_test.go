@@ -0,0 +1,115 @@
+package ledger
+
+import (
+	"testing"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+)
+
+func testCFDI(uuid, rfc, serie, folio, tipo, moneda, cambio string, total float64) complemento.CFDI {
+	c := complemento.CFDI{
+		Serie:             serie,
+		Folio:             folio,
+		TipoDeComprobante: tipo,
+		Moneda:            moneda,
+		TipoCambio:        cambio,
+		Total:             total,
+	}
+	c.Emisor.RFC = rfc
+	c.Complemento.TimbreFiscalDigital.UUID = uuid
+	return c
+}
+
+func TestReconcile(t *testing.T) {
+	cfdis := []complemento.CFDI{
+		testCFDI("AAAA-1", "EKU9003173C9", "A", "1", "I", "MXN", "", 1160),
+		testCFDI("bbbb-2", "EKU9003173C9", "A", "2", "I", "MXN", "", 500),
+		testCFDI("CCCC-3", "URE180429TM6", "B", "7", "I", "MXN", "", 300),
+		testCFDI("DDDD-4", "XIQB891116QE4", "B", "7", "I", "MXN", "", 300),
+		testCFDI("EEEE-5", "EKU9003173C9", "", "90", "I", "USD", "17.50", 100),
+		testCFDI("FFFF-6", "EKU9003173C9", "C", "10", "I", "MXN", "", 200),
+		testCFDI("GGGG-7", "EKU9003173C9", "P", "1", "P", "XXX", "", 0),
+	}
+
+	tests := []struct {
+		name       string
+		entry      Entry
+		status     Status
+		cfdi       string
+		diferencia float64
+	}{
+		{name: "por UUID", entry: Entry{UUID: "AAAA-1", Importe: 1160}, status: Conciliada, cfdi: "AAAA-1"},
+		{name: "UUID en mayúsculas contra XML en minúsculas", entry: Entry{UUID: "BBBB-2", Importe: 500.40}, status: Conciliada, cfdi: "bbbb-2", diferencia: 0.40},
+		{name: "UUID sin XML", entry: Entry{UUID: "ZZZZ-9", Importe: 10}, status: SinXML, diferencia: 10},
+		{name: "serie y folio con RFC", entry: Entry{Serie: "B", Folio: "7", RFC: "XIQB891116QE4", Importe: 300}, status: Conciliada, cfdi: "DDDD-4"},
+		{name: "folio ambiguo sin RFC", entry: Entry{Serie: "B", Folio: "7", Importe: 300}, status: SinXML, diferencia: 300},
+		{name: "serie y folio sin RFC", entry: Entry{Folio: "C-10", Importe: 250}, status: DiferenciaImporte, cfdi: "FFFF-6", diferencia: 50},
+		{name: "dólares comparados en pesos", entry: Entry{Folio: "90", RFC: "EKU9003173C9", Importe: 1750}, status: Conciliada, cfdi: "EEEE-5"},
+		{name: "XML ya conciliado", entry: Entry{UUID: "AAAA-1", Importe: 1160}, status: SinXML, diferencia: 1160},
+	}
+
+	entries := make([]Entry, 0, len(tests))
+	for i, tt := range tests {
+		tt.entry.Row = i + 2
+		entries = append(entries, tt.entry)
+	}
+
+	result := Reconcile(entries, cfdis, 0.5)
+
+	for i, tt := range tests {
+		m := result.Matches[i]
+		if m.Entry == nil || m.Entry.Row != i+2 {
+			t.Fatalf("%s: el resultado %d no es del registro", tt.name, i)
+		}
+		if m.Status != tt.status {
+			t.Errorf("%s: estado = %v, se esperaba %v (%s)", tt.name, m.Status, tt.status, m.Nota)
+		}
+		uuid := ""
+		if m.CFDI != nil {
+			uuid = m.CFDI.Complemento.TimbreFiscalDigital.UUID
+		}
+		if uuid != tt.cfdi {
+			t.Errorf("%s: XML = %q, se esperaba %q", tt.name, uuid, tt.cfdi)
+		}
+		if diff := m.Diferencia - tt.diferencia; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("%s: diferencia = %v, se esperaba %v", tt.name, m.Diferencia, tt.diferencia)
+		}
+	}
+
+	//Al final las facturas sin registro, sin el complemento de pago
+	sinRegistro := result.Matches[len(tests):]
+	want := []string{"CCCC-3"}
+	if len(sinRegistro) != len(want) {
+		t.Fatalf("%d facturas sin registro, se esperaban %d", len(sinRegistro), len(want))
+	}
+	for i, m := range sinRegistro {
+		if m.Status != SinRegistro || m.CFDI.Complemento.TimbreFiscalDigital.UUID != want[i] {
+			t.Errorf("sin registro %d = %v %s, se esperaba %s", i, m.Status, m.CFDI.Complemento.TimbreFiscalDigital.UUID, want[i])
+		}
+		if m.Diferencia != -300 {
+			t.Errorf("diferencia sin registro = %v, se esperaba -300", m.Diferencia)
+		}
+	}
+}
+
+func TestReconcileTolerancia(t *testing.T) {
+	cfdis := []complemento.CFDI{testCFDI("AAAA-1", "EKU9003173C9", "A", "1", "I", "MXN", "", 100)}
+
+	tests := []struct {
+		importe   float64
+		tolerance float64
+		status    Status
+	}{
+		{importe: 100.50, tolerance: 0.5, status: Conciliada},
+		{importe: 100.51, tolerance: 0.5, status: DiferenciaImporte},
+		{importe: 99.99, tolerance: 0, status: DiferenciaImporte},
+		{importe: 100, tolerance: 0, status: Conciliada},
+	}
+
+	for _, tt := range tests {
+		result := Reconcile([]Entry{{UUID: "AAAA-1", Importe: tt.importe}}, cfdis, tt.tolerance)
+		if got := result.Matches[0].Status; got != tt.status {
+			t.Errorf("importe %v tolerancia %v: estado = %v, se esperaba %v", tt.importe, tt.tolerance, got, tt.status)
+		}
+	}
+}
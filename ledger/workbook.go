@@ -0,0 +1,91 @@
+package ledger
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/dannywolfmx/cfdi-xls/sheet"
+)
+
+const workbookSheetResumen = "Resumen"
+
+// Save escribe el libro con el resumen de la conciliación y una hoja por estado
+func (r Result) Save(path string) error {
+	file := sheet.NewFile(path)
+
+	file.RenameSheet(workbookSheetResumen)
+	file.WriteHeader("Estado", "Registros", "Importe contabilidad", "Total XML MXN")
+	for _, status := range Statuses {
+		var importe, total float64
+		for _, m := range r.Matches {
+			if m.Status != status {
+				continue
+			}
+			if m.Entry != nil {
+				importe += m.Entry.Importe
+			}
+			if m.CFDI != nil {
+				total += totalMXN(*m.CFDI)
+			}
+		}
+		file.WriteRow(sheet.Text(status.String()), sheet.Int(r.Count(status)), sheet.Money(importe), sheet.Money(total))
+	}
+	file.WriteTotals("Total")
+
+	for _, status := range Statuses {
+		file.AddSheet(status.String())
+		writeMatches(file, r.Matches, status)
+	}
+
+	return file.Save()
+}
+
+func writeMatches(file *sheet.SheetFile, matches []Match, status Status) {
+	file.WriteHeader("Fila", "UUID", "RFC emisor", "Emisor", "Serie", "Folio", "Fecha", "Importe contabilidad", "Total XML MXN", "Diferencia", "Nota")
+
+	for _, m := range matches {
+		if m.Status != status {
+			continue
+		}
+
+		fila, importe := sheet.Text(""), sheet.Text("")
+		uuid, rfc, serie, folio := "", "", "", ""
+		if m.Entry != nil {
+			//La fila es texto para que no se sume en los totales
+			fila, importe = sheet.Text(strconv.Itoa(m.Entry.Row)), sheet.Money(m.Entry.Importe)
+			uuid, rfc, serie, folio = m.Entry.UUID, m.Entry.RFC, m.Entry.Serie, m.Entry.Folio
+		}
+
+		emisor, fecha, total := "", "", sheet.Text("")
+		if c := m.CFDI; c != nil {
+			uuid, rfc, serie, folio = c.Complemento.TimbreFiscalDigital.UUID, c.Emisor.RFC, c.Serie, c.Folio
+			emisor, fecha, total = c.Emisor.Nombre, c.Fecha, sheet.Money(totalMXN(*c))
+		}
+
+		file.WriteRow(
+			fila,
+			sheet.Text(uuid),
+			sheet.Text(rfc),
+			sheet.Text(emisor),
+			sheet.Text(serie),
+			sheet.Text(folio),
+			dateCell(fecha),
+			importe,
+			total,
+			sheet.Money(m.Diferencia),
+			sheet.Text(m.Nota),
+		)
+	}
+
+	file.WriteTotals("Total")
+}
+
+// dateCell escribe la fecha del CFDI como fecha de excel o el texto si no se puede leer
+func dateCell(fecha string) sheet.Cell {
+	t, err := time.Parse(complemento.FechaLayout, fecha)
+	if err != nil {
+		return sheet.Text(fecha)
+	}
+	return sheet.Date(t)
+}
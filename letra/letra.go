@@ -0,0 +1,199 @@
+package letra
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var unidades = []string{
+	"", "UN", "DOS", "TRES", "CUATRO", "CINCO", "SEIS", "SIETE", "OCHO", "NUEVE",
+	"DIEZ", "ONCE", "DOCE", "TRECE", "CATORCE", "QUINCE", "DIECISÉIS", "DIECISIETE", "DIECIOCHO", "DIECINUEVE",
+	"VEINTE", "VEINTIÚN", "VEINTIDÓS", "VEINTITRÉS", "VEINTICUATRO", "VEINTICINCO", "VEINTISÉIS", "VEINTISIETE", "VEINTIOCHO", "VEINTINUEVE",
+}
+
+var decenas = []string{"", "", "", "TREINTA", "CUARENTA", "CINCUENTA", "SESENTA", "SETENTA", "OCHENTA", "NOVENTA"}
+
+var centenas = []string{
+	"", "CIENTO", "DOSCIENTOS", "TRESCIENTOS", "CUATROCIENTOS", "QUINIENTOS",
+	"SEISCIENTOS", "SETECIENTOS", "OCHOCIENTOS", "NOVECIENTOS",
+}
+
+// escalas son los nombres de cada grupo de seis cifras, el primero son las
+// unidades y los miles
+var escalas = []struct {
+	singular string
+	plural   string
+}{
+	{},
+	{singular: "MILLÓN", plural: "MILLONES"},
+	{singular: "BILLÓN", plural: "BILLONES"},
+	{singular: "TRILLÓN", plural: "TRILLONES"},
+}
+
+// maximo es el primer importe que ya no se puede escribir, un cuatrillón
+const maximo = 1e24
+
+// Numero escribe la cantidad con letra en mayúsculas, con "UN" en lugar de
+// "UNO" porque siempre va antes de la moneda. Llega hasta los trillones, que
+// cubren todo el int64
+func Numero(n int64) string {
+	//Se trabaja con las cifras porque -n no cabe en un int64 con el mínimo
+	if n < 0 {
+		return "MENOS " + conLetra(strings.TrimPrefix(strconv.FormatInt(n, 10), "-"))
+	}
+	return conLetra(strconv.FormatInt(n, 10))
+}
+
+// conLetra escribe un entero dado con sus cifras decimales, de grupo en grupo
+// de seis cifras. No debe pasar de los trillones
+func conLetra(digitos string) string {
+	digitos = strings.TrimLeft(digitos, "0")
+	if digitos == "" {
+		return "CERO"
+	}
+
+	parts := make([]string, 0)
+	grupos := (len(digitos) + 5) / 6
+	for i := grupos - 1; i >= 0; i-- {
+		fin := len(digitos) - 6*i
+		inicio := fin - 6
+		if inicio < 0 {
+			inicio = 0
+		}
+
+		n, _ := strconv.ParseInt(digitos[inicio:fin], 10, 64)
+		escala := escalas[i]
+		switch {
+		case n == 0:
+		case i == 0:
+			parts = append(parts, miles(n))
+		case n == 1:
+			parts = append(parts, "UN "+escala.singular)
+		default:
+			parts = append(parts, miles(n)+" "+escala.plural)
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// miles escribe de 1 a 999,999
+func miles(n int64) string {
+	parts := make([]string, 0, 2)
+
+	if m := n / 1000; m == 1 {
+		parts = append(parts, "MIL")
+	} else if m > 1 {
+		parts = append(parts, cientos(m)+" MIL")
+	}
+	if resto := n % 1000; resto > 0 {
+		parts = append(parts, cientos(resto))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// cientos escribe de 1 a 999
+func cientos(n int64) string {
+	if n == 100 {
+		return "CIEN"
+	}
+
+	parts := make([]string, 0, 2)
+	if c := n / 100; c > 0 {
+		parts = append(parts, centenas[c])
+	}
+
+	switch resto := n % 100; {
+	case resto == 0:
+	case resto < 30:
+		parts = append(parts, unidades[resto])
+	case resto%10 == 0:
+		parts = append(parts, decenas[resto/10])
+	default:
+		parts = append(parts, decenas[resto/10]+" Y "+unidades[resto%10])
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// moneda es cómo se nombra una moneda en el importe con letra
+type moneda struct {
+	singular string
+	plural   string
+	sufijo   string
+}
+
+// Monedas con nombre, las demás se escriben con su clave después de los centavos
+var monedas = map[string]moneda{
+	"MXN": {singular: "PESO", plural: "PESOS", sufijo: "M.N."},
+	"USD": {singular: "DÓLAR", plural: "DÓLARES", sufijo: "USD"},
+	"EUR": {singular: "EURO", plural: "EUROS", sufijo: "EUR"},
+}
+
+// Importe escribe el importe con letra como en la representación impresa del
+// CFDI, por ejemplo "CIENTO VEINTE MIL PESOS 50/100 M.N.". Los centavos se
+// redondean a dos decimales y sin moneda se toma MXN. Regresa vacío si el
+// importe no es un número o llega al cuatrillón
+func Importe(importe float64, clave string) string {
+	if clave == "" {
+		clave = "MXN"
+	}
+
+	abs := math.Abs(importe)
+	if math.IsNaN(abs) || abs >= maximo {
+		return ""
+	}
+
+	cifras := centavos(abs)
+	enteros, fraccion := cifras[:len(cifras)-2], cifras[len(cifras)-2:]
+
+	texto := conLetra(enteros)
+	if importe < 0 && strings.Trim(cifras, "0") != "" {
+		texto = "MENOS " + texto
+	}
+
+	m, ok := monedas[strings.ToUpper(clave)]
+	if !ok {
+		return fmt.Sprintf("%s %s/100 %s", texto, fraccion, strings.ToUpper(clave))
+	}
+
+	nombre := m.plural
+	switch {
+	case enteros == "1":
+		nombre = m.singular
+	case len(enteros) > 6 && strings.HasSuffix(enteros, "000000"):
+		//Los millones, billones y trillones exactos llevan "de": UN MILLÓN DE PESOS
+		nombre = "DE " + m.plural
+	}
+	return fmt.Sprintf("%s %s %s/100 %s", texto, nombre, fraccion, m.sufijo)
+}
+
+// centavos regresa las cifras del importe en centavos, redondeado a la mitad
+// hacia arriba desde su representación decimal más corta para que 1.005 sea
+// 1.01. Se trabaja con las cifras porque desde 9.2e16 los centavos no caben
+// en un int64
+func centavos(importe float64) string {
+	enteros, decimales := strconv.FormatFloat(importe, 'f', -1, 64), ""
+	if i := strings.IndexByte(enteros, '.'); i >= 0 {
+		enteros, decimales = enteros[:i], enteros[i+1:]
+	}
+	decimales += "000"
+
+	cifras := []byte(enteros + decimales[:2])
+	if decimales[2] >= '5' {
+		i := len(cifras) - 1
+		for ; i >= 0 && cifras[i] == '9'; i-- {
+			cifras[i] = '0'
+		}
+		if i < 0 {
+			cifras = append([]byte{'1'}, cifras...)
+		} else {
+			cifras[i]++
+		}
+	}
+
+	return strings.TrimLeft(string(cifras[:len(cifras)-2]), "0") + string(cifras[len(cifras)-2:])
+}
@@ -0,0 +1,77 @@
+package letra
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNumero(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "CERO"},
+		{1, "UN"},
+		{15, "QUINCE"},
+		{21, "VEINTIÚN"},
+		{35, "TREINTA Y CINCO"},
+		{100, "CIEN"},
+		{101, "CIENTO UN"},
+		{999, "NOVECIENTOS NOVENTA Y NUEVE"},
+		{1000, "MIL"},
+		{21000, "VEINTIÚN MIL"},
+		{101000, "CIENTO UN MIL"},
+		{1000000, "UN MILLÓN"},
+		{2000000, "DOS MILLONES"},
+		{1001000, "UN MILLÓN MIL"},
+		{1000000000, "MIL MILLONES"},
+		{1000000000000, "UN BILLÓN"},
+		{1000000000000000000, "UN TRILLÓN"},
+		{-5, "MENOS CINCO"},
+		{math.MaxInt64, "NUEVE TRILLONES DOSCIENTOS VEINTITRÉS MIL TRESCIENTOS SETENTA Y DOS BILLONES TREINTA Y SEIS MIL OCHOCIENTOS CINCUENTA Y CUATRO MILLONES SETECIENTOS SETENTA Y CINCO MIL OCHOCIENTOS SIETE"},
+		{math.MinInt64, "MENOS NUEVE TRILLONES DOSCIENTOS VEINTITRÉS MIL TRESCIENTOS SETENTA Y DOS BILLONES TREINTA Y SEIS MIL OCHOCIENTOS CINCUENTA Y CUATRO MILLONES SETECIENTOS SETENTA Y CINCO MIL OCHOCIENTOS OCHO"},
+	}
+
+	for _, tt := range tests {
+		if got := Numero(tt.n); got != tt.want {
+			t.Errorf("Numero(%d) = %q, se esperaba %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestImporte(t *testing.T) {
+	tests := []struct {
+		importe float64
+		moneda  string
+		want    string
+	}{
+		{0, "MXN", "CERO PESOS 00/100 M.N."},
+		{1, "MXN", "UN PESO 00/100 M.N."},
+		{21, "MXN", "VEINTIÚN PESOS 00/100 M.N."},
+		{0.5, "", "CERO PESOS 50/100 M.N."},
+		{1.005, "MXN", "UN PESO 01/100 M.N."},
+		{0.995, "MXN", "UN PESO 00/100 M.N."},
+		{999999.995, "MXN", "UN MILLÓN DE PESOS 00/100 M.N."},
+		{1234.56, "MXN", "MIL DOSCIENTOS TREINTA Y CUATRO PESOS 56/100 M.N."},
+		{1000000, "MXN", "UN MILLÓN DE PESOS 00/100 M.N."},
+		{1001000, "MXN", "UN MILLÓN MIL PESOS 00/100 M.N."},
+		{1e12, "MXN", "UN BILLÓN DE PESOS 00/100 M.N."},
+		{1e17, "MXN", "CIEN MIL BILLONES DE PESOS 00/100 M.N."},
+		{1e21, "MXN", "MIL TRILLONES DE PESOS 00/100 M.N."},
+		{-21.10, "USD", "MENOS VEINTIÚN DÓLARES 10/100 USD"},
+		{-0.001, "MXN", "CERO PESOS 00/100 M.N."},
+		{1, "usd", "UN DÓLAR 00/100 USD"},
+		{100, "EUR", "CIEN EUROS 00/100 EUR"},
+		{2000000, "EUR", "DOS MILLONES DE EUROS 00/100 EUR"},
+		{15.75, "JPY", "QUINCE 75/100 JPY"},
+		{1e24, "MXN", ""},
+		{math.Inf(1), "MXN", ""},
+		{math.NaN(), "MXN", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Importe(tt.importe, tt.moneda); got != tt.want {
+			t.Errorf("Importe(%v, %q) = %q, se esperaba %q", tt.importe, tt.moneda, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package loader
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+)
+
+// indexVersion se incrementa cuando cambia complemento.CFDI, así los
+// registros guardados con la estructura anterior se vuelven a leer del XML
+const indexVersion = 5
+
+// entry es un CFDI ya leído junto con los datos del archivo de donde salió
+type entry struct {
+	Size    int64
+	ModTime int64
+	Hash    [sha256.Size]byte
+	CFDI    complemento.CFDI
+}
+
+type indexFile struct {
+	Version int
+	Entries map[string]entry
+}
+
+// Index guarda los CFDIs ya leídos por ruta, tamaño, fecha de modificación y
+// hash del contenido, para no volver a leer los archivos que no cambiaron
+type Index struct {
+	path    string
+	entries map[string]entry
+	changed bool
+}
+
+// OpenIndex lee el índice guardado, si no existe, es de otra versión o está
+// dañado se empieza con un índice vacío
+func OpenIndex(path string) *Index {
+	idx := &Index{
+		path:    path,
+		entries: map[string]entry{},
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return idx
+	}
+	defer f.Close()
+
+	var saved indexFile
+	if err := gob.NewDecoder(f).Decode(&saved); err != nil || saved.Version != indexVersion {
+		idx.changed = true
+		return idx
+	}
+
+	idx.entries = saved.Entries
+	return idx
+}
+
+// DefaultIndexPath regresa la ruta del índice de un directorio dentro del
+// directorio de caché del usuario
+func DefaultIndexPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("%x.gob", sha256.Sum256([]byte(abs)))
+	return filepath.Join(cacheDir, "cfdi-xls", name), nil
+}
+
+// lookup regresa el CFDI guardado si el archivo no cambió de tamaño ni de fecha
+func (idx *Index) lookup(path string, info os.FileInfo) (complemento.CFDI, bool) {
+	if idx == nil {
+		return complemento.CFDI{}, false
+	}
+
+	e, ok := idx.entries[path]
+	if !ok || e.Size != info.Size() || e.ModTime != info.ModTime().UnixNano() {
+		return complemento.CFDI{}, false
+	}
+
+	return e.CFDI, true
+}
+
+// lookupHash regresa el CFDI guardado si el contenido es el mismo aunque la
+// fecha haya cambiado (por ejemplo al copiar la carpeta)
+func (idx *Index) lookupHash(path string, hash [sha256.Size]byte) (complemento.CFDI, bool) {
+	if idx == nil {
+		return complemento.CFDI{}, false
+	}
+
+	e, ok := idx.entries[path]
+	if !ok || e.Hash != hash {
+		return complemento.CFDI{}, false
+	}
+
+	return e.CFDI, true
+}
+
+func (idx *Index) store(path string, info os.FileInfo, hash [sha256.Size]byte, cfdi complemento.CFDI) {
+	if idx == nil {
+		return
+	}
+
+	idx.entries[path] = entry{
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+		Hash:    hash,
+		CFDI:    cfdi,
+	}
+	idx.changed = true
+}
+
+// retain borra los registros de los archivos que ya no existen en dir
+func (idx *Index) retain(dir string, seen map[string]bool) {
+	if idx == nil {
+		return
+	}
+
+	for path := range idx.entries {
+		if filepath.Dir(path) == dir && !seen[path] {
+			delete(idx.entries, path)
+			idx.changed = true
+		}
+	}
+}
+
+// Save escribe el índice si hubo cambios, primero en un archivo temporal
+// para no dejarlo a medias
+func (idx *Index) Save() error {
+	if idx == nil || !idx.changed {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(idx.path), 0o755); err != nil {
+		return err
+	}
+
+	tmp := idx.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	err = gob.NewEncoder(f).Encode(indexFile{Version: indexVersion, Entries: idx.entries})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, idx.path); err != nil {
+		return err
+	}
+
+	idx.changed = false
+	return nil
+}
@@ -0,0 +1,179 @@
+package loader
+
+import (
+	"crypto/sha256"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+)
+
+// Parse convierte el contenido de un XML en un CFDI
+func Parse(path string, content []byte) (complemento.CFDI, error) {
+	var cfdi complemento.CFDI
+
+	if err := xml.Unmarshal(content, &cfdi); err != nil {
+		return cfdi, fmt.Errorf("%s: %w", path, err)
+	}
+
+	cfdi.Path = path
+	return cfdi, nil
+}
+
+// IsXML indica si el archivo tiene extensión .xml
+func IsXML(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".xml")
+}
+
+// job es un archivo que no está en el índice y hay que leer
+type job struct {
+	path string
+	info os.FileInfo
+}
+
+type result struct {
+	job
+	hash [sha256.Size]byte
+	cfdi complemento.CFDI
+	err  error
+}
+
+// Load lee todos los XML del directorio. Los archivos que no cambiaron desde
+// la última vez se toman del índice y el resto se leen en paralelo
+func Load(dir string, idx *Index) ([]complemento.CFDI, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := os.ReadDir(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	cfdis := make([]complemento.CFDI, 0, len(files))
+	seen := make(map[string]bool, len(files))
+	pending := make([]job, 0)
+
+	for _, file := range files {
+		if file.IsDir() || !IsXML(file.Name()) {
+			continue
+		}
+
+		pathFile := filepath.Join(abs, file.Name())
+		seen[pathFile] = true
+
+		info, err := file.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		if cfdi, ok := idx.lookup(pathFile, info); ok {
+			cfdis = append(cfdis, cfdi)
+			continue
+		}
+
+		pending = append(pending, job{path: pathFile, info: info})
+	}
+
+	for _, r := range parseAll(pending, idx) {
+		if r.err != nil {
+			return nil, r.err
+		}
+		idx.store(r.path, r.info, r.hash, r.cfdi)
+		cfdis = append(cfdis, r.cfdi)
+	}
+
+	idx.retain(abs, seen)
+
+	return cfdis, nil
+}
+
+// Each lee los XML del directorio uno por uno en orden de nombre y llama fn
+// con cada CFDI sin guardarlos, para procesar carpetas que no caben en memoria.
+// Si fn regresa un error se deja de leer
+func Each(dir string, fn func(complemento.CFDI) error) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	files, err := os.ReadDir(abs)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if file.IsDir() || !IsXML(file.Name()) {
+			continue
+		}
+
+		pathFile := filepath.Join(abs, file.Name())
+		content, err := os.ReadFile(pathFile)
+		if err != nil {
+			return err
+		}
+
+		cfdi, err := Parse(pathFile, content)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(cfdi); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseAll lee los archivos pendientes con un worker por CPU
+func parseAll(pending []job, idx *Index) []result {
+	results := make([]result, len(pending))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = parseJob(pending[i], idx)
+			}
+		}()
+	}
+
+	for i := range pending {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+func parseJob(j job, idx *Index) result {
+	r := result{job: j}
+
+	content, err := os.ReadFile(j.path)
+	if err != nil {
+		r.err = err
+		return r
+	}
+
+	r.hash = sha256.Sum256(content)
+
+	//El archivo cambió de fecha pero no de contenido
+	if cfdi, ok := idx.lookupHash(j.path, r.hash); ok {
+		r.cfdi = cfdi
+		return r
+	}
+
+	r.cfdi, r.err = Parse(j.path, content)
+	return r
+}
@@ -0,0 +1,144 @@
+package loader
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metadata es un renglón de los archivos de metadata de la descarga masiva del
+// SAT, trae los datos principales de la factura sin el XML
+type Metadata struct {
+	UUID             string
+	RfcEmisor        string
+	NombreEmisor     string
+	RfcReceptor      string
+	NombreReceptor   string
+	FechaEmision     string
+	Monto            float64
+	Efecto           string
+	Cancelado        bool
+	FechaCancelacion string
+
+	// Path es el archivo de metadata y ModTime cuándo se descargó
+	Path    string
+	ModTime time.Time
+}
+
+// Estatus es el estado de la factura en la metadata
+func (m Metadata) Estatus() string {
+	if m.Cancelado {
+		return "Cancelado"
+	}
+	return "Vigente"
+}
+
+// IsMetadata indica si el archivo puede ser de metadata, la extensión es .txt
+func IsMetadata(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".txt")
+}
+
+// LoadMetadata lee los archivos de metadata del directorio, los .txt que no
+// tienen el encabezado de la metadata se ignoran
+func LoadMetadata(dir string) ([]Metadata, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := os.ReadDir(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	all := make([]Metadata, 0)
+	for _, file := range files {
+		if file.IsDir() || !IsMetadata(file.Name()) {
+			continue
+		}
+
+		rows, err := ReadMetadata(filepath.Join(abs, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, rows...)
+	}
+
+	return all, nil
+}
+
+// ReadMetadata lee un archivo de metadata separado por ~ o por |, regresa
+// nil si el archivo no es de metadata
+func ReadMetadata(path string) ([]Metadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	if !scanner.Scan() {
+		return nil, scanner.Err()
+	}
+	header := strings.TrimPrefix(scanner.Text(), "\ufeff")
+
+	delimiter := "~"
+	if !strings.Contains(header, delimiter) {
+		delimiter = "|"
+	}
+
+	index := map[string]int{}
+	for i, name := range strings.Split(header, delimiter) {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := index["uuid"]; !ok {
+		return nil, nil
+	}
+
+	rows := make([]Metadata, 0)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line, delimiter)
+		value := func(column string) string {
+			i, ok := index[column]
+			if !ok || i >= len(fields) {
+				return ""
+			}
+			return strings.TrimSpace(fields[i])
+		}
+
+		monto, _ := strconv.ParseFloat(value("monto"), 64)
+		estatus := strings.ToLower(value("estatus"))
+
+		rows = append(rows, Metadata{
+			UUID:             strings.ToUpper(value("uuid")),
+			RfcEmisor:        value("rfcemisor"),
+			NombreEmisor:     value("nombreemisor"),
+			RfcReceptor:      value("rfcreceptor"),
+			NombreReceptor:   value("nombrereceptor"),
+			FechaEmision:     value("fechaemision"),
+			Monto:            monto,
+			Efecto:           value("efectocomprobante"),
+			Cancelado:        estatus == "0" || estatus == "cancelado",
+			FechaCancelacion: value("fechacancelacion"),
+			Path:             path,
+			ModTime:          info.ModTime(),
+		})
+	}
+
+	return rows, scanner.Err()
+}
@@ -0,0 +1,122 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const metadataHeader = "Uuid~RfcEmisor~NombreEmisor~RfcReceptor~NombreReceptor~RfcPac~FechaEmision~FechaCertificacionSat~Monto~EfectoComprobante~Estatus~FechaCancelacion"
+
+func writeMetadata(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "metadata.txt")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		uuids   []string
+		estados []bool
+	}{
+		{
+			name: "separado por tilde",
+			content: metadataHeader + "\n" +
+				"6e3a4b2c-1d5f-4a7b-9c8d-0e1f2a3b4c5d~EKU9003173C9~ESCUELA KEMPER URGATE~XAXX010101000~PUBLICO EN GENERAL~SPR190613I52~2023-05-10 12:30:00~2023-05-10 12:31:05~1160.00~I~1~\n" +
+				"\n" +
+				"1F2E3D4C-5B6A-4978-8A9B-0C1D2E3F4A5B~EKU9003173C9~ESCUELA KEMPER URGATE~XAXX010101000~PUBLICO EN GENERAL~SPR190613I52~2023-05-11 09:00:00~2023-05-11 09:01:00~500.00~I~0~2023-06-01 10:00:00\n",
+			uuids:   []string{"6E3A4B2C-1D5F-4A7B-9C8D-0E1F2A3B4C5D", "1F2E3D4C-5B6A-4978-8A9B-0C1D2E3F4A5B"},
+			estados: []bool{false, true},
+		},
+		{
+			name: "separado por barra con BOM",
+			content: "\ufeffUuid|RfcEmisor|Monto|Estatus|FechaCancelacion\r\n" +
+				"AAAA-1|EKU9003173C9|100.00|Vigente|\r\n" +
+				"BBBB-2|EKU9003173C9|200.00|Cancelado|2023-06-01 10:00:00\r\n" +
+				"CCCC-3|EKU9003173C9|300.00|CANCELADO|2023-06-02 10:00:00\r\n",
+			uuids:   []string{"AAAA-1", "BBBB-2", "CCCC-3"},
+			estados: []bool{false, true, true},
+		},
+		{
+			name:    "sin encabezado de metadata",
+			content: "notas de la descarga\nAAAA-1~100.00\n",
+		},
+		{
+			name:    "archivo vacío",
+			content: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeMetadata(t, tt.content)
+
+			rows, err := ReadMetadata(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != len(tt.uuids) {
+				t.Fatalf("%d renglones, se esperaban %d: %+v", len(rows), len(tt.uuids), rows)
+			}
+			for i, m := range rows {
+				if m.UUID != tt.uuids[i] {
+					t.Errorf("renglón %d: UUID = %q, se esperaba %q", i, m.UUID, tt.uuids[i])
+				}
+				if m.Cancelado != tt.estados[i] {
+					t.Errorf("renglón %d: Cancelado = %v, se esperaba %v", i, m.Cancelado, tt.estados[i])
+				}
+				if m.Path != path {
+					t.Errorf("renglón %d: Path = %q, se esperaba %q", i, m.Path, path)
+				}
+			}
+		})
+	}
+}
+
+func TestReadMetadataCampos(t *testing.T) {
+	path := writeMetadata(t, metadataHeader+"\n"+
+		" 6e3a4b2c-1d5f-4a7b-9c8d-0e1f2a3b4c5d ~EKU9003173C9~ESCUELA KEMPER URGATE~XAXX010101000~PUBLICO EN GENERAL~SPR190613I52~2023-05-10 12:30:00~2023-05-10 12:31:05~1160.50~I~0~2023-06-01 10:00:00\n"+
+		//Un renglón corto no debe tronar, las columnas que faltan quedan vacías
+		"AAAA-1~EKU9003173C9\n")
+
+	rows, err := ReadMetadata(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("%d renglones, se esperaban 2", len(rows))
+	}
+
+	want := Metadata{
+		UUID:             "6E3A4B2C-1D5F-4A7B-9C8D-0E1F2A3B4C5D",
+		RfcEmisor:        "EKU9003173C9",
+		NombreEmisor:     "ESCUELA KEMPER URGATE",
+		RfcReceptor:      "XAXX010101000",
+		NombreReceptor:   "PUBLICO EN GENERAL",
+		FechaEmision:     "2023-05-10 12:30:00",
+		Monto:            1160.50,
+		Efecto:           "I",
+		Cancelado:        true,
+		FechaCancelacion: "2023-06-01 10:00:00",
+		Path:             path,
+		ModTime:          rows[0].ModTime,
+	}
+	if rows[0] != want {
+		t.Errorf("renglón = %+v\nse esperaba %+v", rows[0], want)
+	}
+	if got := rows[0].Estatus(); got != "Cancelado" {
+		t.Errorf("Estatus = %q, se esperaba Cancelado", got)
+	}
+
+	if rows[1].UUID != "AAAA-1" || rows[1].Monto != 0 || rows[1].Cancelado {
+		t.Errorf("renglón corto = %+v", rows[1])
+	}
+	if got := rows[1].Estatus(); got != "Vigente" {
+		t.Errorf("Estatus = %q, se esperaba Vigente", got)
+	}
+}
@@ -0,0 +1,221 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	// Tiempo de espera después del último evento para leer un archivo,
+	// los correos y copias escriben el archivo en varias partes
+	watchDebounce = 500 * time.Millisecond
+
+	// Cada cuánto se revisan los directorios cuando no hay notificaciones del sistema
+	watchPollInterval = 2 * time.Second
+)
+
+// Update son los cambios encontrados en los directorios vigilados
+type Update struct {
+	// CFDIs nuevos o que cambiaron
+	CFDIs []complemento.CFDI
+	// Removed son las rutas de los XML que se borraron
+	Removed []string
+	// Errors son los archivos que no se pudieron leer, se vuelven a intentar al cambiar
+	Errors []error
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// Watcher vigila los directorios y manda por Updates los XML nuevos o modificados
+type Watcher struct {
+	Updates <-chan Update
+
+	updates chan Update
+	dirs    []string
+	done    chan struct{}
+	once    sync.Once
+	notify  *fsnotify.Watcher
+}
+
+// Watch empieza a vigilar los directorios. Usa las notificaciones del sistema
+// de archivos y si no están disponibles (por ejemplo en carpetas de red)
+// revisa los directorios cada cierto tiempo
+func Watch(dirs []string) (*Watcher, error) {
+	abs := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		a, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		abs = append(abs, a)
+	}
+
+	updates := make(chan Update)
+	w := &Watcher{
+		Updates: updates,
+		updates: updates,
+		dirs:    abs,
+		done:    make(chan struct{}),
+	}
+
+	if notify, err := newNotifyWatcher(abs); err == nil {
+		w.notify = notify
+		go w.notifyLoop()
+	} else {
+		go w.pollLoop()
+	}
+
+	return w, nil
+}
+
+func newNotifyWatcher(dirs []string) (*fsnotify.Watcher, error) {
+	notify, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dir := range dirs {
+		if err := notify.Add(dir); err != nil {
+			notify.Close()
+			return nil, err
+		}
+	}
+
+	return notify, nil
+}
+
+// Close deja de vigilar los directorios
+func (w *Watcher) Close() {
+	w.once.Do(func() {
+		close(w.done)
+		if w.notify != nil {
+			w.notify.Close()
+		}
+	})
+}
+
+// notifyLoop junta los eventos del sistema y lee los archivos cuando dejan de cambiar
+func (w *Watcher) notifyLoop() {
+	pending := map[string]bool{}
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-w.notify.Events:
+			if !ok {
+				return
+			}
+			if !IsXML(event.Name) {
+				continue
+			}
+			pending[event.Name] = true
+			timer.Reset(watchDebounce)
+		case _, ok := <-w.notify.Errors:
+			if !ok {
+				return
+			}
+			//Si se pierden eventos se cambia a revisar los directorios
+			w.notify.Close()
+			go w.pollLoop()
+			return
+		case <-timer.C:
+			w.send(pending)
+			pending = map[string]bool{}
+		}
+	}
+}
+
+// pollLoop compara el tamaño y la fecha de los archivos en cada revisión
+func (w *Watcher) pollLoop() {
+	snapshot := w.snapshot()
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			current := w.snapshot()
+			pending := map[string]bool{}
+
+			for path, state := range current {
+				if before, ok := snapshot[path]; !ok || before != state {
+					pending[path] = true
+				}
+			}
+			for path := range snapshot {
+				if _, ok := current[path]; !ok {
+					pending[path] = true
+				}
+			}
+
+			snapshot = current
+			w.send(pending)
+		}
+	}
+}
+
+func (w *Watcher) snapshot() map[string]fileState {
+	states := map[string]fileState{}
+	for _, dir := range w.dirs {
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if file.IsDir() || !IsXML(file.Name()) {
+				continue
+			}
+			info, err := file.Info()
+			if err != nil {
+				continue
+			}
+			states[filepath.Join(dir, file.Name())] = fileState{size: info.Size(), modTime: info.ModTime()}
+		}
+	}
+	return states
+}
+
+// send lee los archivos pendientes y manda un solo Update con todos los cambios
+func (w *Watcher) send(pending map[string]bool) {
+	if len(pending) == 0 {
+		return
+	}
+
+	var update Update
+	for path := range pending {
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			update.Removed = append(update.Removed, path)
+			continue
+		}
+		if err != nil {
+			update.Errors = append(update.Errors, err)
+			continue
+		}
+
+		cfdi, err := Parse(path, content)
+		if err != nil {
+			update.Errors = append(update.Errors, err)
+			continue
+		}
+		update.CFDIs = append(update.CFDIs, cfdi)
+	}
+
+	select {
+	case w.updates <- update:
+	case <-w.done:
+	}
+}
@@ -2,75 +2,176 @@ package main
 
 import (
 	"encoding/xml"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"path"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/dannywolfmx/cfdi-xls/estatus"
+	"github.com/dannywolfmx/cfdi-xls/impresa"
+	"github.com/dannywolfmx/cfdi-xls/ledger"
+	"github.com/dannywolfmx/cfdi-xls/loader"
+	"github.com/dannywolfmx/cfdi-xls/reglas"
+	"github.com/dannywolfmx/cfdi-xls/sello"
+	"github.com/dannywolfmx/cfdi-xls/sheet"
 	"github.com/dannywolfmx/cfdi-xls/table"
+	"github.com/dannywolfmx/cfdi-xls/tags"
 )
 
 const DIR_NAME = "./cfdis"
 
-func main() {
-	//Check if the directory exists
-	if !directoryExist(DIR_NAME) {
-		ex, err := os.Executable()
-		if err != nil {
-			log.Fatal("Error al detectar el path del ejecutable")
-		}
-		path := filepath.Dir(ex)
-		log.Fatalf("No existe el directorio %s en la ruta actual %s", DIR_NAME, path)
-	}
+// Archivo con las etiquetas y notas de las facturas, se guarda junto a los cfdis
+const TAGS_FILE_NAME = ".cfdi-xls-etiquetas.json"
 
-	//Get the files in the directory
-	files, err := os.ReadDir(DIR_NAME)
+// Archivo con el último estatus consultado de cada factura, junto a los cfdis
+const ESTATUS_FILE_NAME = ".cfdi-xls-estatus.json"
+
+// dirList permite repetir la opción -dir
+type dirList []string
 
+func (d *dirList) String() string {
+	return strings.Join(*d, ",")
+}
+
+func (d *dirList) Set(value string) error {
+	*d = append(*d, value)
+	return nil
+}
+
+func main() {
+	var dirs dirList
+	flag.Var(&dirs, "dir", "Directorio con los XML, se puede repetir (por defecto "+DIR_NAME+")")
+	watch := flag.Bool("watch", false, "Vigilar los directorios y agregar las facturas nuevas sin reiniciar")
+	exportar := flag.String("exportar", "", "Exportar todas las facturas a este archivo sin abrir la tabla")
+	pdf := flag.String("pdf", "", "Generar la representación impresa en PDF de todas las facturas en esta carpeta sin abrir la tabla")
+	formato := flag.String("formato", "", "Formato de -exportar: xlsx, csv, tsv, json o jsonl (por defecto según la extensión)")
+	delimitador := flag.String("delimitador", ",", "Separador de columnas en CSV, por ejemplo ; para excel en español")
+	bom := flag.Bool("bom", false, "Agregar la marca UTF-8 al inicio de los CSV para que excel respete los acentos")
+	conciliar := flag.String("conciliar", "", "Conciliar las facturas con la contabilidad de este archivo .xlsx o .csv")
+	columnas := flag.String("columnas", "", "Columnas de -conciliar, por ejemplo uuid=Folio fiscal,rfc=RFC,importe=F,hoja=Compras")
+	tolerancia := flag.Float64("tolerancia", 0.01, "Diferencia de importe que se acepta al conciliar")
+	certificadosSAT := flag.String("certificados-sat", "", "Directorio con los certificados del SAT (.cer) para revisar el sello del timbre")
+	resultado := flag.String("resultado", "conciliacion.xlsx", "Libro donde se escribe el resultado de -conciliar")
+	consultarEstatus := flag.String("estatus", "", "Consultar si las facturas están vigentes o canceladas en este archivo (UUID y estado por línea) o URL")
+	vigencia := flag.Duration("estatus-vigencia", 24*time.Hour, "Tiempo antes de volver a consultar el estatus de una factura vigente")
+	incluirCanceladas := flag.Bool("incluir-canceladas", false, "Sumar las facturas canceladas en el resumen")
+	omitirReglas := flag.String("omitir-reglas", "", "Reglas de negocio que no se revisan, separadas por coma, por ejemplo uso-regimen,ppd-forma")
+	flag.Parse()
+
+	delimiter, err := parseDelimiter(*delimitador)
 	if err != nil {
 		log.Fatal(err)
 	}
+	table.SetExportOptions(sheet.Options{Delimiter: delimiter, BOM: *bom})
 
-	//ComplementoDePagoPrint(files)
-	CFDIPrint(files)
+	verifier, err := sello.NewVerifier(*certificadosSAT)
+	if err != nil {
+		log.Fatal(err)
+	}
+	table.SetVerifier(verifier)
 
-	//Prevent the console from closing
-	fmt.Scanln()
-}
+	engine := reglas.Default()
+	for _, id := range strings.Split(*omitirReglas, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			if _, ok := engine.Rule(id); !ok {
+				log.Fatalf("No existe la regla %q", id)
+			}
+			engine.Remove(id)
+		}
+	}
+	table.SetReglas(engine)
 
-func CFDIPrint(files []os.DirEntry) {
-	cfdis := make([]complemento.CFDI, 0)
+	if len(dirs) == 0 {
+		dirs = dirList{DIR_NAME}
+	}
 
-	for _, file := range files {
-		//Read the file
-		if file.IsDir() {
-			continue
+	//Check if the directories exist
+	for _, dir := range dirs {
+		if !directoryExist(dir) {
+			ex, err := os.Executable()
+			if err != nil {
+				log.Fatal("Error al detectar el path del ejecutable")
+			}
+			path := filepath.Dir(ex)
+			log.Fatalf("No existe el directorio %s en la ruta actual %s", dir, path)
 		}
-		pathFile := path.Join(DIR_NAME, file.Name())
+	}
 
-		//check if the extension is a XML
-		if filepath.Ext(pathFile) != ".xml" {
-			continue
+	estados, err := estatus.OpenCache(path.Join(dirs[0], ESTATUS_FILE_NAME))
+	if err != nil {
+		log.Fatal(err)
+	}
+	table.SetEstatus(estados, *incluirCanceladas)
+
+	if *pdf != "" {
+		CFDIPDF(dirs, *pdf)
+		return
+	}
+
+	if *exportar != "" {
+		format, err := exportFormat(*exportar, *formato)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if table.IsTemplate(*exportar) {
+			CFDIExportSplit(dirs, *exportar, format)
+		} else {
+			CFDIExport(dirs, *exportar, format)
 		}
+		return
+	}
 
-		content, err := os.ReadFile(pathFile)
+	cfdis := loadCFDIS(dirs)
+
+	CFDIMetadata(dirs, cfdis, estados)
+
+	if *consultarEstatus != "" {
+		CFDIEstatus(cfdis, estados, *consultarEstatus, *vigencia)
+	}
 
+	if *conciliar != "" {
+		mapping, err := ledger.ParseMapping(*columnas)
 		if err != nil {
 			log.Fatal(err)
 		}
+		CFDIConciliar(cfdis, *conciliar, mapping, *tolerancia, *resultado)
+	}
+
+	//ComplementoDePagoPrint(DIR_NAME)
+	CFDIPrint(dirs, cfdis, *watch)
 
-		var cfdi complemento.CFDI
+	//Prevent the console from closing
+	fmt.Scanln()
+}
 
-		err = xml.Unmarshal(content, &cfdi)
+// loadCFDIS lee las facturas de todos los directorios ordenadas por fecha
+func loadCFDIS(dirs []string) []complemento.CFDI {
+	cfdis := make([]complemento.CFDI, 0)
 
+	for _, dir := range dirs {
+		//The index keeps the parsed CFDIs so only new or changed files are read again
+		var idx *loader.Index
+		if indexPath, err := loader.DefaultIndexPath(dir); err == nil {
+			idx = loader.OpenIndex(indexPath)
+		}
+
+		loaded, err := loader.Load(dir, idx)
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		cfdis = append(cfdis, cfdi)
+		if err := idx.Save(); err != nil {
+			log.Printf("No se pudo guardar el índice de facturas: %v", err)
+		}
+
+		cfdis = append(cfdis, loaded...)
 	}
 
 	if len(cfdis) == 0 {
@@ -90,6 +191,139 @@ func CFDIPrint(files []os.DirEntry) {
 		return fechaI.Before(fechaJ)
 	})
 
+	return cfdis
+}
+
+// CFDIConciliar compara las facturas con la contabilidad, escribe el libro
+// con el resultado y lo deja listo para la pestaña de conciliación
+func CFDIConciliar(cfdis []complemento.CFDI, file string, mapping ledger.Mapping, tolerancia float64, resultado string) {
+	entries, err := ledger.Read(file, mapping)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	r := ledger.Reconcile(entries, cfdis, tolerancia)
+	if err := r.Save(resultado); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("%d registros de %s\n", len(entries), file)
+	for _, status := range ledger.Statuses {
+		fmt.Printf("%8d  %s\n", r.Count(status), status)
+	}
+	fmt.Printf("Resultado en %s\n", resultado)
+
+	table.SetConciliacion(r)
+}
+
+// CFDIMetadata lee la metadata del SAT de los directorios, guarda el estatus
+// que trae de cada factura y busca las facturas que no tienen XML, las
+// canceladas se listan con su estatus
+func CFDIMetadata(dirs []string, cfdis []complemento.CFDI, cache *estatus.Cache) {
+	loaded := make(map[string]bool, len(cfdis))
+	for _, c := range cfdis {
+		loaded[strings.ToUpper(c.Complemento.TimbreFiscalDigital.UUID)] = true
+	}
+
+	seen := map[string]bool{}
+	canceladas := 0
+	sinXML := make([]loader.Metadata, 0)
+	for _, dir := range dirs {
+		rows, err := loader.LoadMetadata(dir)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, m := range rows {
+			r := estatus.Result{Estado: estatus.Vigente, Consultado: m.ModTime}
+			if m.Cancelado {
+				r = estatus.Result{Estado: estatus.Cancelado, Consultado: m.ModTime, FechaCancelacion: m.FechaCancelacion}
+			}
+			cache.Merge(m.UUID, r)
+
+			//La misma factura puede venir en varias descargas
+			if seen[m.UUID] {
+				continue
+			}
+			seen[m.UUID] = true
+
+			if m.Cancelado {
+				canceladas++
+			}
+			if !loaded[m.UUID] {
+				sinXML = append(sinXML, m)
+			}
+		}
+	}
+
+	if len(seen) == 0 {
+		return
+	}
+
+	if err := cache.Save(); err != nil {
+		log.Printf("No se pudo guardar el estatus de las facturas: %v", err)
+	}
+
+	fmt.Printf("%d facturas en la metadata del SAT, %d canceladas, %d sin XML\n", len(seen), canceladas, len(sinXML))
+	for i, m := range sinXML {
+		if i == 20 {
+			fmt.Printf("  ... y %d más en la hoja %q del reporte de Excel\n", len(sinXML)-i, "Sin XML")
+			break
+		}
+		fmt.Printf("  %s  %-9s %-13s %12.2f  %s\n", m.UUID, m.Estatus(), m.RfcEmisor, m.Monto, m.NombreEmisor)
+	}
+
+	table.SetMetadataSinXML(sinXML)
+}
+
+// CFDIEstatus consulta si las facturas siguen vigentes, solo las que no se han
+// consultado o cuya consulta venció, y guarda los estados para la siguiente vez
+func CFDIEstatus(cfdis []complemento.CFDI, cache *estatus.Cache, source string, vigencia time.Duration) {
+	provider, err := estatusProvider(source)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	checker := estatus.NewChecker(provider, cache, vigencia)
+	counts := map[estatus.Estado]int{}
+	fallidas := 0
+	for _, c := range cfdis {
+		r, err := checker.Check(c)
+		if err != nil {
+			//Solo se muestra el primer error, casi siempre es el mismo para todas
+			if fallidas == 0 {
+				log.Printf("No se pudo consultar el estatus de %s: %v", c.Complemento.TimbreFiscalDigital.UUID, err)
+			}
+			fallidas++
+		}
+		counts[r.Estado]++
+	}
+
+	if err := cache.Save(); err != nil {
+		log.Printf("No se pudo guardar el estatus de las facturas: %v", err)
+	}
+
+	fmt.Printf("Estatus de %d facturas\n", len(cfdis))
+	for _, e := range append(estatus.Estados, estatus.Desconocido) {
+		if counts[e] > 0 {
+			fmt.Printf("%8d  %s\n", counts[e], e)
+		}
+	}
+	if fallidas > 0 {
+		fmt.Printf("%d consultas fallaron, se usa el último estatus conocido\n", fallidas)
+	}
+}
+
+// estatusProvider consulta por HTTP si source es una URL, si no lee los
+// estados del archivo
+func estatusProvider(source string) (estatus.Provider, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return estatus.NewHTTPProvider(source)
+	}
+	return estatus.OpenFileProvider(source)
+}
+
+func CFDIPrint(dirs []string, cfdis []complemento.CFDI, watch bool) {
 	cfdisPUE := make([]complemento.CFDI, 0)
 	cfdisPPD := make([]complemento.CFDI, 0)
 
@@ -111,7 +345,22 @@ func CFDIPrint(files []os.DirEntry) {
 	//	subTotal := 0.0
 	//	formasDePago := make(map[string]int)
 
-	table.PrintTable(cfdis)
+	store, err := tags.Open(path.Join(dirs[0], TAGS_FILE_NAME))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var updates <-chan loader.Update
+	if watch {
+		watcher, err := loader.Watch(dirs)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer watcher.Close()
+		updates = watcher.Updates
+	}
+
+	table.PrintTable(cfdis, store, updates)
 
 	//	fmt.Println("Total Subtotal: ", subTotal)
 	//	fmt.Println("Total: ", total)
@@ -134,18 +383,122 @@ func CFDIPrint(files []os.DirEntry) {
 
 }
 
-func ComplementoDePagoPrint(files []os.DirEntry) {
+// exportFormat usa el formato indicado o el de la extensión del archivo
+func exportFormat(file, formato string) (sheet.Format, error) {
+	if formato != "" {
+		return sheet.ParseFormat(formato)
+	}
+	return sheet.FormatFromPath(file)
+}
+
+// parseDelimiter acepta un solo carácter, \t o tab para el tabulador
+func parseDelimiter(value string) (rune, error) {
+	if value == `\t` || value == "tab" {
+		return '\t', nil
+	}
+	if utf8.RuneCountInString(value) != 1 {
+		return 0, fmt.Errorf("el delimitador debe ser un solo carácter: %q", value)
+	}
+	r, _ := utf8.DecodeRuneInString(value)
+	return r, nil
+}
+
+// CFDIExportSplit escribe un archivo por cada ruta distinta que sale de la
+// plantilla y muestra cuántas facturas quedaron en cada uno
+func CFDIExportSplit(dirs []string, template string, format sheet.Format) {
+	cfdis := loadCFDIS(dirs)
+
+	store, err := tags.Open(path.Join(dirs[0], TAGS_FILE_NAME))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	written, err := table.ExportSplit(template, format, store, cfdis)
+	if err != nil && len(written) == 0 {
+		log.Fatal(err)
+	}
+
+	facturas := 0
+	for _, file := range written {
+		fmt.Printf("%8d facturas %16.2f  %s\n", file.Facturas, file.Total, file.Path)
+		facturas += file.Facturas
+	}
+	fmt.Printf("%d archivos con %d facturas\n", len(written), facturas)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// CFDIExport escribe las facturas de los directorios conforme se leen, la
+// memoria no crece con la cantidad de facturas
+func CFDIExport(dirs []string, file string, format sheet.Format) {
+	store, err := tags.Open(path.Join(dirs[0], TAGS_FILE_NAME))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	count := 0
+	err = table.ExportStream(file, format, store, func(fn func(complemento.CFDI) error) error {
+		for _, dir := range dirs {
+			err := loader.Each(dir, func(cfdi complemento.CFDI) error {
+				count++
+				return fn(cfdi)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("%d facturas exportadas a %s\n", count, file)
+}
+
+// CFDIPDF genera la representación impresa de cada factura conforme se leen,
+// el PDF lleva el nombre del XML o el UUID si ya hay otro con ese nombre
+func CFDIPDF(dirs []string, out string) {
+	if err := os.MkdirAll(out, 0o755); err != nil {
+		log.Fatal(err)
+	}
+
+	count := 0
+	nombres := impresa.Nombres{}
+	for _, dir := range dirs {
+		err := loader.Each(dir, func(cfdi complemento.CFDI) error {
+			count++
+			return impresa.WriteFile(cfdi, filepath.Join(out, nombres.FileName(cfdi)))
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	fmt.Printf("%d PDF generados en %s\n", count, out)
+}
+
+func ComplementoDePagoPrint(dir string) {
 	pagos := make([]complemento.PrintablePagos, 0)
 
+	//Get the files in the directory
+	files, err := os.ReadDir(dir)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	for _, file := range files {
 		//Read the file
 		if file.IsDir() {
 			continue
 		}
-		pathFile := path.Join(DIR_NAME, file.Name())
+		pathFile := path.Join(dir, file.Name())
 
 		//check if the extension is a XML
-		if filepath.Ext(pathFile) != ".xml" {
+		if !loader.IsXML(pathFile) {
 			continue
 		}
 
@@ -164,24 +517,17 @@ func ComplementoDePagoPrint(files []os.DirEntry) {
 		}
 
 		//Transform the data to the struct PrintablePagos
-		printablePagos := complemento.PrintablePagos{
-			Emisor:        complementoDePago.Emisor.Nombre,
-			Receptor:      complementoDePago.Receptor.Nombre,
-			FechaTimbrado: transformFecha(complementoDePago.Fecha),
-		}
-
-		pago20 := complementoDePago.Complemento.Pagos20
-
-		for _, documento := range pago20.Pago.DoctoRelacionado {
-			printablePago := complemento.PrintablePago{
-				FechaPago:     transformFecha(pago20.Pago.FechaPago),
-				ImportePagado: documento.ImpPagado,
-				Folio:         documento.Folio,
-			}
-			printablePagos.Pagos = append(printablePagos.Pagos, printablePago)
+		printablePagos, err := complemento.NewPrintablePagos(
+			complementoDePago.Emisor.Nombre,
+			complementoDePago.Receptor.Nombre,
+			transformFecha(complementoDePago.Fecha),
+			complementoDePago.Complemento.Pagos20,
+		)
+		if err != nil {
+			log.Fatal(err)
 		}
 
-		pagos = append(pagos, printablePagos)
+		pagos = append(pagos, printablePagos...)
 	}
 	if len(pagos) == 0 {
 		log.Fatal("No se encontraron pagos")
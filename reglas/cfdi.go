@@ -0,0 +1,135 @@
+package reglas
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+)
+
+// Diferencia que se acepta por redondeo al comparar importes
+const tolerancia = 0.01
+
+// Default regresa un motor con las reglas incluidas
+func Default() *Engine {
+	return NewEngine(
+		ReglaSubTotal,
+		ReglaTotal,
+		ReglaPUEFormaPago,
+		ReglaPPDFormaPago,
+		ReglaUsoRegimen,
+	)
+}
+
+// ReglaSubTotal revisa que el SubTotal sea la suma del importe de los conceptos
+var ReglaSubTotal = Rule{
+	ID:          "subtotal",
+	Descripcion: "SubTotal distinto a la suma de los conceptos",
+	Severity:    Error,
+	Check: func(c complemento.CFDI) []string {
+		if len(c.Conceptos) == 0 {
+			return nil
+		}
+
+		suma := 0.0
+		for _, concepto := range c.Conceptos {
+			suma += concepto.Importe
+		}
+		if distinto(c.SubTotal, suma) {
+			return []string{fmt.Sprintf("el SubTotal %.2f no es la suma de los conceptos %.2f", c.SubTotal, suma)}
+		}
+		return nil
+	},
+}
+
+// ReglaTotal revisa que el Total sea SubTotal - Descuento + traslados - retenciones,
+// incluidos los impuestos locales
+var ReglaTotal = Rule{
+	ID:          "total",
+	Descripcion: "Total distinto a SubTotal - Descuento + impuestos",
+	Severity:    Error,
+	Check: func(c complemento.CFDI) []string {
+		locales := c.Complemento.ImpuestosLocales
+		total := c.SubTotal - c.Descuento +
+			c.Impuestos.TotalImpuestosTrasladados - c.Impuestos.TotalImpuestosRetenidos +
+			locales.TotalTraslados - locales.TotalRetenciones
+
+		if distinto(c.Total, total) {
+			return []string{fmt.Sprintf("el Total %.2f no corresponde al SubTotal menos descuento más impuestos %.2f", c.Total, total)}
+		}
+		return nil
+	},
+}
+
+// ReglaPUEFormaPago revisa que un pago en una sola exhibición diga con qué se pagó
+var ReglaPUEFormaPago = Rule{
+	ID:          "pue-99",
+	Descripcion: "PUE con forma de pago 99 Por definir",
+	Severity:    Error,
+	Check: func(c complemento.CFDI) []string {
+		if c.MetodoPago == "PUE" && c.FormaPago == "99" {
+			return []string{"método PUE con forma de pago 99 (Por definir), debe indicar cómo se pagó"}
+		}
+		return nil
+	},
+}
+
+// ReglaPPDFormaPago revisa que un pago en parcialidades use la forma de pago 99,
+// la forma real va en cada complemento de pago
+var ReglaPPDFormaPago = Rule{
+	ID:          "ppd-forma",
+	Descripcion: "PPD con forma de pago distinta de 99",
+	Severity:    Error,
+	Check: func(c complemento.CFDI) []string {
+		if c.MetodoPago == "PPD" && c.FormaPago != "" && c.FormaPago != "99" {
+			return []string{fmt.Sprintf("método PPD con forma de pago %s, debe ser 99 (Por definir)", c.FormaPago)}
+		}
+		return nil
+	},
+}
+
+// ReglaUsoRegimen revisa el uso del CFDI contra el régimen fiscal del receptor
+// con el catálogo c_UsoCFDI, solo aplica desde el CFDI 4.0
+var ReglaUsoRegimen = Rule{
+	ID:          "uso-regimen",
+	Descripcion: "Uso CFDI no permitido para el régimen del receptor",
+	Severity:    Error,
+	Check: func(c complemento.CFDI) []string {
+		regimen := c.Receptor.RegimenFiscal
+		permitidos, ok := usoRegimenes[c.Receptor.UsoCFDI]
+		if regimen == "" || !ok {
+			return nil
+		}
+
+		for _, r := range permitidos {
+			if r == regimen {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("el uso %s no se permite al régimen %s del receptor", c.Receptor.UsoCFDI, regimen)}
+	},
+}
+
+func distinto(a, b float64) bool {
+	return math.Abs(a-b) > tolerancia+1e-9
+}
+
+// Regímenes fiscales del receptor que admite cada uso del CFDI según c_UsoCFDI
+var usoRegimenes = map[string][]string{}
+
+func init() {
+	general := []string{"601", "603", "606", "612", "620", "621", "622", "623", "624", "625", "626"}
+	deducciones := []string{"605", "606", "607", "608", "611", "612", "614", "615", "625"}
+	todos := []string{"601", "603", "605", "606", "607", "608", "610", "611", "612", "614", "615", "616", "620", "621", "622", "623", "624", "625", "626"}
+
+	for _, uso := range strings.Fields("G01 G02 G03 I01 I02 I03 I04 I05 I06 I07 I08") {
+		usoRegimenes[uso] = general
+	}
+	for _, uso := range strings.Fields("D01 D02 D03 D04 D05 D06 D07 D08 D09 D10") {
+		usoRegimenes[uso] = deducciones
+	}
+	usoRegimenes["S01"] = todos
+	usoRegimenes["CP01"] = todos
+	usoRegimenes["CN01"] = []string{"605"}
+}
@@ -0,0 +1,113 @@
+package reglas
+
+import (
+	"fmt"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+)
+
+// Severity indica qué tan grave es un problema
+type Severity int
+
+const (
+	// Aviso es algo que conviene revisar pero que el SAT acepta
+	Aviso Severity = iota
+	// Error es un comprobante que no cumple las reglas del SAT
+	Error
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "Error"
+	}
+	return "Aviso"
+}
+
+// Rule es una revisión del comprobante, Check regresa un mensaje por cada
+// problema que encuentra
+type Rule struct {
+	ID          string
+	Descripcion string
+	Severity    Severity
+	Check       func(c complemento.CFDI) []string
+}
+
+// Problem es lo que encontró una regla en un comprobante
+type Problem struct {
+	Regla    string
+	Severity Severity
+	Mensaje  string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s", p.Severity, p.Mensaje)
+}
+
+// Engine aplica sus reglas en el orden en que se agregaron
+type Engine struct {
+	rules []Rule
+}
+
+// NewEngine crea el motor con las reglas indicadas, Default tiene las que
+// vienen incluidas
+func NewEngine(rules ...Rule) *Engine {
+	return &Engine{rules: append([]Rule{}, rules...)}
+}
+
+// Add agrega una regla, si ya hay una con el mismo ID la reemplaza
+func (e *Engine) Add(rule Rule) {
+	for i, r := range e.rules {
+		if r.ID == rule.ID {
+			e.rules[i] = rule
+			return
+		}
+	}
+	e.rules = append(e.rules, rule)
+}
+
+// Remove quita la regla con el ID
+func (e *Engine) Remove(id string) {
+	for i, r := range e.rules {
+		if r.ID == id {
+			e.rules = append(e.rules[:i], e.rules[i+1:]...)
+			return
+		}
+	}
+}
+
+// Rules regresa las reglas del motor
+func (e *Engine) Rules() []Rule {
+	return append([]Rule{}, e.rules...)
+}
+
+// Rule busca la regla con el ID
+func (e *Engine) Rule(id string) (Rule, bool) {
+	for _, r := range e.rules {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Check aplica todas las reglas al comprobante
+func (e *Engine) Check(c complemento.CFDI) []Problem {
+	problems := make([]Problem, 0)
+	for _, r := range e.rules {
+		for _, mensaje := range r.Check(c) {
+			problems = append(problems, Problem{Regla: r.ID, Severity: r.Severity, Mensaje: mensaje})
+		}
+	}
+	return problems
+}
+
+// Max regresa la severidad más alta de los problemas
+func Max(problems []Problem) Severity {
+	max := Aviso
+	for _, p := range problems {
+		if p.Severity > max {
+			max = p.Severity
+		}
+	}
+	return max
+}
@@ -0,0 +1,129 @@
+package reglas
+
+import (
+	"testing"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+)
+
+func conceptos(importes ...float64) []complemento.Concepto {
+	list := make([]complemento.Concepto, 0, len(importes))
+	for _, importe := range importes {
+		list = append(list, complemento.Concepto{Importe: importe})
+	}
+	return list
+}
+
+func TestReglas(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  Rule
+		cfdi  func(c *complemento.CFDI)
+		falla bool
+	}{
+		{name: "subtotal es la suma", rule: ReglaSubTotal, cfdi: func(c *complemento.CFDI) {
+			c.SubTotal, c.Conceptos = 150.50, conceptos(100, 50.50)
+		}},
+		{name: "subtotal con redondeo de un centavo", rule: ReglaSubTotal, cfdi: func(c *complemento.CFDI) {
+			c.SubTotal, c.Conceptos = 150.51, conceptos(100, 50.50)
+		}},
+		{name: "subtotal distinto", rule: ReglaSubTotal, cfdi: func(c *complemento.CFDI) {
+			c.SubTotal, c.Conceptos = 160, conceptos(100, 50.50)
+		}, falla: true},
+		{name: "subtotal sin conceptos", rule: ReglaSubTotal, cfdi: func(c *complemento.CFDI) {
+			c.SubTotal = 160
+		}},
+
+		{name: "total con impuestos", rule: ReglaTotal, cfdi: func(c *complemento.CFDI) {
+			c.SubTotal, c.Descuento, c.Total = 1000, 100, 858
+			c.Impuestos.TotalImpuestosTrasladados, c.Impuestos.TotalImpuestosRetenidos = 144, 186
+		}},
+		{name: "total distinto", rule: ReglaTotal, cfdi: func(c *complemento.CFDI) {
+			c.SubTotal, c.Total = 1000, 1000
+			c.Impuestos.TotalImpuestosTrasladados = 160
+		}, falla: true},
+		{name: "total con impuestos locales", rule: ReglaTotal, cfdi: func(c *complemento.CFDI) {
+			c.SubTotal, c.Total = 1000, 1175
+			c.Impuestos.TotalImpuestosTrasladados = 160
+			c.Complemento.ImpuestosLocales.TotalTraslados = 20
+			c.Complemento.ImpuestosLocales.TotalRetenciones = 5
+		}},
+		{name: "total sin sumar los impuestos locales", rule: ReglaTotal, cfdi: func(c *complemento.CFDI) {
+			c.SubTotal, c.Total = 1000, 1160
+			c.Impuestos.TotalImpuestosTrasladados = 160
+			c.Complemento.ImpuestosLocales.TotalTraslados = 20
+		}, falla: true},
+
+		{name: "PUE con forma 99", rule: ReglaPUEFormaPago, cfdi: func(c *complemento.CFDI) {
+			c.MetodoPago, c.FormaPago = "PUE", "99"
+		}, falla: true},
+		{name: "PUE con transferencia", rule: ReglaPUEFormaPago, cfdi: func(c *complemento.CFDI) {
+			c.MetodoPago, c.FormaPago = "PUE", "03"
+		}},
+		{name: "PPD con forma 99 en regla PUE", rule: ReglaPUEFormaPago, cfdi: func(c *complemento.CFDI) {
+			c.MetodoPago, c.FormaPago = "PPD", "99"
+		}},
+
+		{name: "PPD con forma 99", rule: ReglaPPDFormaPago, cfdi: func(c *complemento.CFDI) {
+			c.MetodoPago, c.FormaPago = "PPD", "99"
+		}},
+		{name: "PPD con transferencia", rule: ReglaPPDFormaPago, cfdi: func(c *complemento.CFDI) {
+			c.MetodoPago, c.FormaPago = "PPD", "03"
+		}, falla: true},
+		{name: "PPD sin forma de pago", rule: ReglaPPDFormaPago, cfdi: func(c *complemento.CFDI) {
+			c.MetodoPago = "PPD"
+		}},
+
+		{name: "3.3 sin régimen del receptor", rule: ReglaUsoRegimen, cfdi: func(c *complemento.CFDI) {
+			c.Version, c.Receptor.UsoCFDI = "3.3", "CN01"
+		}},
+		{name: "4.0 gastos en general persona moral", rule: ReglaUsoRegimen, cfdi: func(c *complemento.CFDI) {
+			c.Version, c.Receptor.UsoCFDI, c.Receptor.RegimenFiscal = "4.0", "G03", "601"
+		}},
+		{name: "4.0 deducción personal a persona moral", rule: ReglaUsoRegimen, cfdi: func(c *complemento.CFDI) {
+			c.Version, c.Receptor.UsoCFDI, c.Receptor.RegimenFiscal = "4.0", "D01", "601"
+		}, falla: true},
+		{name: "4.0 nómina a asalariado", rule: ReglaUsoRegimen, cfdi: func(c *complemento.CFDI) {
+			c.Version, c.Receptor.UsoCFDI, c.Receptor.RegimenFiscal = "4.0", "CN01", "605"
+		}},
+		{name: "4.0 nómina a persona moral", rule: ReglaUsoRegimen, cfdi: func(c *complemento.CFDI) {
+			c.Version, c.Receptor.UsoCFDI, c.Receptor.RegimenFiscal = "4.0", "CN01", "601"
+		}, falla: true},
+		{name: "4.0 uso fuera del catálogo", rule: ReglaUsoRegimen, cfdi: func(c *complemento.CFDI) {
+			c.Version, c.Receptor.UsoCFDI, c.Receptor.RegimenFiscal = "4.0", "ZZZ", "601"
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c complemento.CFDI
+			tt.cfdi(&c)
+
+			mensajes := tt.rule.Check(c)
+			if falla := len(mensajes) > 0; falla != tt.falla {
+				t.Errorf("%s: falla = %v, se esperaba %v %v", tt.rule.ID, falla, tt.falla, mensajes)
+			}
+		})
+	}
+}
+
+func TestEngine(t *testing.T) {
+	c := complemento.CFDI{MetodoPago: "PUE", FormaPago: "99", SubTotal: 10, Total: 10, Conceptos: conceptos(10)}
+
+	e := Default()
+	problems := e.Check(c)
+	if len(problems) != 1 || problems[0].Regla != ReglaPUEFormaPago.ID || problems[0].Severity != Error {
+		t.Fatalf("problemas = %v, se esperaba solo %s", problems, ReglaPUEFormaPago.ID)
+	}
+
+	e.Remove(ReglaPUEFormaPago.ID)
+	if problems := e.Check(c); len(problems) != 0 {
+		t.Errorf("sin la regla quedan problemas %v", problems)
+	}
+
+	e.Add(Rule{ID: "aviso", Severity: Aviso, Check: func(complemento.CFDI) []string { return []string{"revisar"} }})
+	problems = e.Check(c)
+	if len(problems) != 1 || Max(problems) != Aviso {
+		t.Errorf("problemas = %v, se esperaba un aviso", problems)
+	}
+}
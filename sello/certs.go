@@ -0,0 +1,96 @@
+package sello
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CertStore son los certificados del SAT por número de certificado
+type CertStore struct {
+	certs map[string]*x509.Certificate
+}
+
+// OpenCertStore lee los .cer, .crt y .pem del directorio, en DER o en PEM.
+// El número se toma del certificado, el nombre del archivo no importa
+func OpenCertStore(dir string) (*CertStore, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &CertStore{certs: map[string]*x509.Certificate{}}
+	for _, file := range files {
+		switch strings.ToLower(filepath.Ext(file.Name())) {
+		case ".cer", ".crt", ".pem":
+		default:
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		//Los archivos que no son certificados se ignoran
+		for _, cert := range readCertificates(content) {
+			s.certs[certificateNumber(cert)] = cert
+		}
+	}
+
+	return s, nil
+}
+
+// Get regresa el certificado con el número, un CertStore nil no tiene certificados
+func (s *CertStore) Get(number string) (*x509.Certificate, bool) {
+	if s == nil {
+		return nil, false
+	}
+	cert, ok := s.certs[number]
+	return cert, ok
+}
+
+// Len regresa cuántos certificados se cargaron
+func (s *CertStore) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.certs)
+}
+
+func readCertificates(content []byte) []*x509.Certificate {
+	certs := make([]*x509.Certificate, 0, 1)
+
+	if cert, err := x509.ParseCertificate(content); err == nil {
+		return append(certs, cert)
+	}
+
+	for {
+		var block *pem.Block
+		block, content = pem.Decode(content)
+		if block == nil {
+			return certs
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certs = append(certs, cert)
+		}
+	}
+}
+
+// parseCertificate lee el certificado en base64 del atributo Certificado
+func parseCertificate(value string) (*x509.Certificate, error) {
+	der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+// certificateNumber regresa el número de certificado del SAT, el número de
+// serie trae los 20 dígitos como texto
+func certificateNumber(cert *x509.Certificate) string {
+	return string(cert.SerialNumber.Bytes())
+}
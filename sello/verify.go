@@ -0,0 +1,183 @@
+package sello
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+)
+
+// Status es el resultado de revisar un sello
+type Status int
+
+const (
+	// Desconocido no se pudo revisar, por ejemplo falta el certificado
+	Desconocido Status = iota
+	Valido
+	Invalido
+	// NoSoportado la versión del comprobante o del timbre, o alguno de sus
+	// complementos, no se sabe revisar. Los CFDI 3.2 y anteriores usan SHA-1 y
+	// otra cadena original
+	NoSoportado
+)
+
+func (s Status) String() string {
+	switch s {
+	case Valido:
+		return "Válido"
+	case Invalido:
+		return "Inválido"
+	case NoSoportado:
+		return "No soportada"
+	}
+	return "Desconocido"
+}
+
+// Result es la revisión del sello del emisor y del timbre del SAT
+type Result struct {
+	Sello  Status
+	Timbre Status
+	// Motivo explica por qué el sello no es válido o no se pudo revisar
+	Motivo string
+}
+
+// Status es válido si los dos sellos lo son, inválido si alguno no lo es, no
+// soportado si alguno no se sabe revisar y desconocido en los demás casos
+func (r Result) Status() Status {
+	if r.Sello == Invalido || r.Timbre == Invalido {
+		return Invalido
+	}
+	if r.Sello == Valido && r.Timbre == Valido {
+		return Valido
+	}
+	if r.Sello == NoSoportado || r.Timbre == NoSoportado {
+		return NoSoportado
+	}
+	return Desconocido
+}
+
+func (r Result) String() string {
+	if r.Motivo == "" {
+		return r.Status().String()
+	}
+	return r.Status().String() + ": " + r.Motivo
+}
+
+// Verifier revisa los sellos sin conexión, los certificados del SAT se buscan
+// en un directorio local
+type Verifier struct {
+	sat *CertStore
+}
+
+// NewVerifier usa los certificados del SAT del directorio, si dir está vacío
+// el timbre queda como desconocido
+func NewVerifier(dir string) (*Verifier, error) {
+	v := &Verifier{}
+	if dir == "" {
+		return v, nil
+	}
+
+	store, err := OpenCertStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	v.sat = store
+
+	return v, nil
+}
+
+// Verify revisa el sello del emisor contra su certificado y el timbre contra
+// el certificado del SAT, el XML se vuelve a leer de c.Path
+func (v *Verifier) Verify(c complemento.CFDI) Result {
+	r := Result{}
+	r.Sello, r.Motivo = v.verifySello(c)
+
+	timbre, motivo := v.verifyTimbre(c)
+	r.Timbre = timbre
+	if r.Motivo == "" || (r.Sello != Invalido && timbre == Invalido) {
+		r.Motivo = motivo
+	}
+
+	return r
+}
+
+func (v *Verifier) verifySello(c complemento.CFDI) (Status, string) {
+	if c.Version != "3.3" && c.Version != "4.0" {
+		return NoSoportado, "versión " + c.Version + " no soportada"
+	}
+	if c.Sello == "" || c.Certificado == "" {
+		return Desconocido, "el comprobante no tiene sello o certificado"
+	}
+
+	cert, err := parseCertificate(c.Certificado)
+	if err != nil {
+		return Invalido, "certificado del emisor dañado"
+	}
+	if c.NoCertificado != "" && certificateNumber(cert) != c.NoCertificado {
+		return Invalido, "el NoCertificado no es el del certificado"
+	}
+
+	content, err := os.ReadFile(c.Path)
+	if err != nil {
+		return Desconocido, err.Error()
+	}
+	cadena, err := complemento.CadenaOriginal(content)
+	if errors.Is(err, complemento.ErrComplementoSinRegla) {
+		return NoSoportado, err.Error()
+	}
+	if err != nil {
+		return Desconocido, err.Error()
+	}
+
+	if err := verifySignature(cert, cadena, c.Sello); err != nil {
+		return Invalido, "el sello del emisor no corresponde al comprobante"
+	}
+	return Valido, ""
+}
+
+func (v *Verifier) verifyTimbre(c complemento.CFDI) (Status, string) {
+	tfd := c.Complemento.TimbreFiscalDigital
+	if tfd.Version != "1.1" {
+		return NoSoportado, "timbre " + tfd.Version + " no soportado"
+	}
+	if tfd.SelloSAT == "" {
+		return Desconocido, "el timbre no tiene sello del SAT"
+	}
+	//El timbre sella el sello del emisor, si no es el mismo es de otro comprobante
+	if strings.Join(strings.Fields(tfd.SelloCFD), "") != strings.Join(strings.Fields(c.Sello), "") {
+		return Invalido, "el timbre es de otro sello"
+	}
+
+	cert, ok := v.sat.Get(tfd.NoCertificadoSAT)
+	if !ok {
+		return Desconocido, "falta el certificado del SAT " + tfd.NoCertificadoSAT
+	}
+
+	if err := verifySignature(cert, tfd.CadenaOriginal(), tfd.SelloSAT); err != nil {
+		return Invalido, "el sello del SAT no corresponde al timbre"
+	}
+	return Valido, ""
+}
+
+// verifySignature revisa el sello RSA con SHA-256 que usan los CFDI 3.3 y 4.0 y el timbre 1.1
+func verifySignature(cert *x509.Certificate, cadena, sello string) error {
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("el certificado %s no es RSA", certificateNumber(cert))
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(sello), ""))
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256([]byte(cadena))
+	return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature)
+}
@@ -0,0 +1,78 @@
+package sheet
+
+import "time"
+
+// CellKind indica el formato con el que se escribe una celda
+type CellKind int
+
+const (
+	TextCell CellKind = iota
+	MoneyCell
+	IntCell
+	DateCell
+	// LinkCell es una dirección que en excel se escribe como hipervínculo
+	LinkCell
+)
+
+// Cell es el valor de una celda junto con su formato
+type Cell struct {
+	Kind  CellKind
+	Value interface{}
+	// Moneda del importe, una columna con importes en varias monedas no se suma
+	Moneda string
+}
+
+func Text(value string) Cell {
+	return Cell{Kind: TextCell, Value: value}
+}
+
+func Money(value float64) Cell {
+	return Cell{Kind: MoneyCell, Value: value}
+}
+
+// MoneyIn es un importe en la moneda indicada
+func MoneyIn(value float64, moneda string) Cell {
+	return Cell{Kind: MoneyCell, Value: value, Moneda: moneda}
+}
+
+func Int(value int) Cell {
+	return Cell{Kind: IntCell, Value: value}
+}
+
+func Date(value time.Time) Cell {
+	return Cell{Kind: DateCell, Value: value}
+}
+
+func Link(url string) Cell {
+	return Cell{Kind: LinkCell, Value: url}
+}
+
+// monedaMixta marca las columnas con importes en más de una moneda
+const monedaMixta = "*"
+
+// monedas recuerda la moneda de los importes de cada columna para no sumar
+// pesos con dólares en la fila de totales
+type monedas map[int]string
+
+func (m monedas) add(column int, moneda string) {
+	if moneda == "" {
+		return
+	}
+	if prev, ok := m[column]; !ok {
+		m[column] = moneda
+	} else if prev != moneda {
+		m[column] = monedaMixta
+	}
+}
+
+func (m monedas) mixta(column int) bool {
+	return m[column] == monedaMixta
+}
+
+// RowWriter escribe filas completas, lo cumplen SheetFile y StreamFile para
+// que la misma exportación funcione en memoria o por streaming
+type RowWriter interface {
+	WriteHeader(titles ...string)
+	WriteRow(cells ...Cell)
+	WriteTotals(label string)
+}
@@ -0,0 +1,99 @@
+package sheet
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Marca de orden de bytes de UTF-8, excel la necesita para abrir los acentos bien
+const utf8BOM = "\xEF\xBB\xBF"
+
+// Formato de las fechas en los archivos de texto
+const textDateFormat = "2006-01-02T15:04:05"
+
+// CSVFile escribe filas separadas por un delimitador, los importes se
+// escriben sin signo ni comas para que otros programas los lean como números
+type CSVFile struct {
+	Err error
+
+	file   *os.File
+	buffer *bufio.Writer
+	writer *csv.Writer
+}
+
+func newCSVFile(path string, delimiter rune, bom bool) (*CSVFile, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &CSVFile{file: file, buffer: bufio.NewWriter(file)}
+	c.writer = csv.NewWriter(c.buffer)
+	if delimiter != 0 {
+		c.writer.Comma = delimiter
+	}
+
+	if bom {
+		_, c.Err = c.buffer.WriteString(utf8BOM)
+	}
+
+	return c, nil
+}
+
+func (c *CSVFile) WriteHeader(titles ...string) {
+	c.write(titles)
+}
+
+func (c *CSVFile) WriteRow(cells ...Cell) {
+	record := make([]string, 0, len(cells))
+	for _, cell := range cells {
+		record = append(record, cellText(cell))
+	}
+	c.write(record)
+}
+
+// WriteTotals no escribe nada, en CSV los totales se mezclarían con los datos
+func (c *CSVFile) WriteTotals(label string) {}
+
+func (c *CSVFile) write(record []string) {
+	if c.Err != nil {
+		return
+	}
+	c.Err = c.writer.Write(record)
+}
+
+// Save termina de escribir y cierra el archivo
+func (c *CSVFile) Save() error {
+	defer c.file.Close()
+
+	if c.Err != nil {
+		return c.Err
+	}
+
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		return err
+	}
+	if err := c.buffer.Flush(); err != nil {
+		return err
+	}
+
+	return c.file.Close()
+}
+
+// cellText convierte la celda a texto sin formato de moneda ni de excel
+func cellText(cell Cell) string {
+	switch cell.Kind {
+	case MoneyCell:
+		return strconv.FormatFloat(cell.Value.(float64), 'f', 2, 64)
+	case IntCell:
+		return strconv.Itoa(cell.Value.(int))
+	case DateCell:
+		return cell.Value.(time.Time).Format(textDateFormat)
+	}
+	return fmt.Sprint(cell.Value)
+}
@@ -2,19 +2,37 @@ package sheet
 
 import (
 	"fmt"
+	"sort"
+	"time"
+	"unicode/utf8"
 
 	"github.com/xuri/excelize/v2"
 )
 
 type SheetFile struct {
 	actualSheet  string
-	actualColumn byte
-	actualRow    uint
+	actualColumn int
+	actualRow    int
 	Err          error
 
+	styles  styles
+	layouts map[string]*layout
 	*excelize.File
 }
 
+// layout guarda lo que se escribió en cada hoja para darle formato al guardar
+type layout struct {
+	// headerRow es la fila de encabezados, 0 si la hoja no tiene
+	headerRow  int
+	lastColumn int
+	// lastDataRow es la última fila antes de los totales
+	lastDataRow int
+	widths      map[int]float64
+	// sums tiene el estilo de cada columna que se suma en la fila de totales
+	sums    map[int]int
+	monedas monedas
+}
+
 func NewFile(path string) *SheetFile {
 	sheet := "Sheet1"
 	file := excelize.NewFile()
@@ -22,26 +40,279 @@ func NewFile(path string) *SheetFile {
 	index := file.NewSheet(sheet)
 	file.SetActiveSheet(index)
 
-	return &SheetFile{
+	b := &SheetFile{
 		File:         file,
 		actualSheet:  sheet,
-		actualColumn: 'A',
+		actualColumn: 1,
 		actualRow:    1,
+		layouts:      map[string]*layout{sheet: newLayout()},
 	}
+	b.styles, b.Err = newStyles(file)
+
+	return b
 }
 
-func (b *SheetFile) SetCellRight(value string) *SheetFile {
+func newLayout() *layout {
+	return &layout{
+		widths:  map[int]float64{},
+		sums:    map[int]int{},
+		monedas: monedas{},
+	}
+}
+
+func (b *SheetFile) layout() *layout {
+	return b.layouts[b.actualSheet]
+}
+
+// axis regresa la celda actual, por ejemplo AB12
+func (b *SheetFile) axis() string {
+	axis, err := excelize.CoordinatesToCellName(b.actualColumn, b.actualRow)
+	if err != nil && b.Err == nil {
+		b.Err = err
+	}
+	return axis
+}
+
+// setCellRight escribe el valor con su estilo, recuerda el ancho que necesita
+// la columna y avanza a la derecha
+func (b *SheetFile) setCellRight(value interface{}, style int, width int) *SheetFile {
 	//Check if previes cells has an error
 	if b.Err != nil {
 		return b
 	}
-	axis := fmt.Sprintf("%c%d", b.actualColumn, b.actualRow)
-	b.Err = b.SetCellValue(b.actualSheet, axis, value)
+
+	axis := b.axis()
+	if b.Err = b.SetCellValue(b.actualSheet, axis, value); b.Err != nil {
+		return b
+	}
+	if style != 0 {
+		b.Err = b.SetCellStyle(b.actualSheet, axis, axis, style)
+	}
+
+	//Los totales no entran en el autofiltro, se escriben directo en SetTotalsRow
+	l := b.layout()
+	if b.actualColumn > l.lastColumn {
+		l.lastColumn = b.actualColumn
+	}
+	if b.actualRow > l.lastDataRow {
+		l.lastDataRow = b.actualRow
+	}
+	if w := float64(width) + cellPadding; w > l.widths[b.actualColumn] {
+		l.widths[b.actualColumn] = w
+	}
+
 	b.NextColumn()
 
 	return b
 }
 
+func (b *SheetFile) SetCellRight(value string) *SheetFile {
+	return b.setCellRight(value, 0, utf8.RuneCountInString(value))
+}
+
+// SetHeaderRight escribe un encabezado, la fila de encabezados queda fija y con autofiltro
+func (b *SheetFile) SetHeaderRight(title string) *SheetFile {
+	b.layout().headerRow = b.actualRow
+	return b.setCellRight(title, b.styles.header, utf8.RuneCountInString(title))
+}
+
+// SetMoneyRight escribe un importe con formato de moneda, la columna se suma en los totales
+func (b *SheetFile) SetMoneyRight(value float64) *SheetFile {
+	b.layout().sums[b.actualColumn] = b.styles.totalMoney
+	return b.setCellRight(value, b.styles.money, moneyWidth(value))
+}
+
+// SetIntRight escribe una cantidad entera, la columna se suma en los totales
+func (b *SheetFile) SetIntRight(value int) *SheetFile {
+	b.layout().sums[b.actualColumn] = b.styles.totalInteger
+	return b.setCellRight(value, b.styles.integer, len(fmt.Sprint(value)))
+}
+
+// SetDateRight escribe una fecha con formato de fecha y hora
+func (b *SheetFile) SetDateRight(value time.Time) *SheetFile {
+	return b.setCellRight(value, b.styles.date, len(dateFormat))
+}
+
+// SetLinkRight escribe la dirección con la fórmula HIPERVINCULO igual que
+// StreamFile, excel solo admite 65,530 hipervínculos por hoja. Si está vacía
+// la celda queda en blanco
+func (b *SheetFile) SetLinkRight(url string) *SheetFile {
+	if url == "" || b.Err != nil {
+		return b.SetCellRight(url)
+	}
+
+	axis := b.axis()
+	if b.setCellRight(url, b.styles.link, utf8.RuneCountInString(url)); b.Err != nil {
+		return b
+	}
+	b.Err = b.SetCellFormula(b.actualSheet, axis, hyperlinkFormula(url))
+	return b
+}
+
+// moneyWidth calcula los caracteres de un importe con signo, comas y centavos
+func moneyWidth(value float64) int {
+	digits := len(fmt.Sprintf("%.0f", value))
+	return 1 + digits + (digits-1)/3 + 3
+}
+
+// SetTotalsRow agrega una fila con la etiqueta y la suma de cada columna de
+// importes o cantidades escritas desde los encabezados, las columnas con
+// importes en varias monedas se dejan sin suma
+func (b *SheetFile) SetTotalsRow(label string) *SheetFile {
+	if b.Err != nil {
+		return b
+	}
+
+	l := b.layout()
+	first, last := l.headerRow+1, l.lastDataRow
+
+	//Si la fila actual ya tiene celdas los totales van en la siguiente
+	if b.actualColumn > 1 {
+		b.MoveRowDownAndResetColumn()
+	}
+	axis := b.axis()
+	if b.Err = b.SetCellValue(b.actualSheet, axis, label); b.Err != nil {
+		return b
+	}
+	b.Err = b.SetCellStyle(b.actualSheet, axis, axis, b.styles.totalLabel)
+
+	columns := make([]int, 0, len(l.sums))
+	for column := range l.sums {
+		if !l.monedas.mixta(column) {
+			columns = append(columns, column)
+		}
+	}
+	sort.Ints(columns)
+
+	for _, column := range columns {
+		if b.Err != nil {
+			return b
+		}
+		name, err := excelize.ColumnNumberToName(column)
+		if err != nil {
+			b.Err = err
+			return b
+		}
+		axis := fmt.Sprintf("%s%d", name, b.actualRow)
+		if b.Err = b.SetCellFormula(b.actualSheet, axis, fmt.Sprintf("SUM(%s%d:%s%d)", name, first, name, last)); b.Err != nil {
+			return b
+		}
+		b.Err = b.SetCellStyle(b.actualSheet, axis, axis, l.sums[column])
+	}
+
+	return b
+}
+
+// WriteHeader escribe la fila de encabezados y pasa a la siguiente fila
+func (b *SheetFile) WriteHeader(titles ...string) {
+	for _, title := range titles {
+		b.SetHeaderRight(title)
+	}
+	b.MoveRowDownAndResetColumn()
+}
+
+// WriteRow escribe las celdas en la fila actual con su formato y pasa a la siguiente fila
+func (b *SheetFile) WriteRow(cells ...Cell) {
+	for _, cell := range cells {
+		switch cell.Kind {
+		case MoneyCell:
+			b.layout().monedas.add(b.actualColumn, cell.Moneda)
+			b.SetMoneyRight(cell.Value.(float64))
+		case IntCell:
+			b.SetIntRight(cell.Value.(int))
+		case DateCell:
+			b.SetDateRight(cell.Value.(time.Time))
+		case LinkCell:
+			b.SetLinkRight(cell.Value.(string))
+		default:
+			b.SetCellRight(fmt.Sprint(cell.Value))
+		}
+	}
+	b.MoveRowDownAndResetColumn()
+}
+
+// WriteTotals escribe la fila de totales y pasa a la siguiente fila
+func (b *SheetFile) WriteTotals(label string) {
+	b.SetTotalsRow(label)
+	b.MoveRowDownAndResetColumn()
+}
+
+// RenameSheet cambia el nombre de la hoja actual
+func (b *SheetFile) RenameSheet(name string) *SheetFile {
+	if b.Err != nil {
+		return b
+	}
+	b.SetSheetName(b.actualSheet, name)
+	b.layouts[name] = b.layouts[b.actualSheet]
+	delete(b.layouts, b.actualSheet)
+	b.actualSheet = name
+
+	return b
+}
+
+// AddSheet agrega una hoja y mueve el cursor a su primera celda
+func (b *SheetFile) AddSheet(name string) *SheetFile {
+	if b.Err != nil {
+		return b
+	}
+	b.NewSheet(name)
+	b.layouts[name] = newLayout()
+	b.actualSheet = name
+	b.actualColumn = 1
+	b.actualRow = 1
+
+	return b
+}
+
+// Save aplica el ancho de las columnas, el autofiltro y fija los encabezados
+// de cada hoja antes de guardar el archivo
+func (b *SheetFile) Save() error {
+	if b.Err != nil {
+		return b.Err
+	}
+
+	for sheet, l := range b.layouts {
+		if err := b.formatSheet(sheet, l); err != nil {
+			return err
+		}
+	}
+
+	return b.File.Save()
+}
+
+func (b *SheetFile) formatSheet(sheet string, l *layout) error {
+	for column, width := range l.widths {
+		name, err := excelize.ColumnNumberToName(column)
+		if err != nil {
+			return err
+		}
+		if width > maxColumnWidth {
+			width = maxColumnWidth
+		}
+		if err := b.SetColWidth(sheet, name, name, width); err != nil {
+			return err
+		}
+	}
+
+	if l.headerRow == 0 || l.lastColumn == 0 {
+		return nil
+	}
+
+	first, err := excelize.CoordinatesToCellName(1, l.headerRow)
+	if err != nil {
+		return err
+	}
+	last, err := excelize.CoordinatesToCellName(l.lastColumn, l.lastDataRow)
+	if err != nil {
+		return err
+	}
+	if err := b.AutoFilter(sheet, first, last, ""); err != nil {
+		return err
+	}
+
+	return b.SetPanes(sheet, freezePanes(l.headerRow))
+}
+
 func (b *SheetFile) NextColumn() {
 	b.actualColumn++
 }
@@ -51,12 +322,12 @@ func (b *SheetFile) MoveRowDown() {
 }
 
 func (b *SheetFile) MoveRowUpAndResetColumn() {
-	b.actualColumn = 'A'
+	b.actualColumn = 1
 	b.MoveRowUp()
 }
 
 func (b *SheetFile) MoveRowDownAndResetColumn() {
-	b.actualColumn = 'A'
+	b.actualColumn = 1
 	b.MoveRowDown()
 }
 
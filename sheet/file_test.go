@@ -0,0 +1,133 @@
+package sheet
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// writeTotalsBook escribe 28 columnas para pasar de la Z: la AA tiene totales
+// en dólares y en pesos, la AB los mismos convertidos a pesos y la AC cantidades
+func writeTotalsBook(t *testing.T, w RowWriter) {
+	t.Helper()
+
+	titles := make([]string, 0, 29)
+	for i := 1; i <= 26; i++ {
+		titles = append(titles, fmt.Sprintf("Texto %d", i))
+	}
+	titles = append(titles, "Total", "Total MXN", "Conceptos")
+	w.WriteHeader(titles...)
+
+	rows := []struct {
+		total  float64
+		moneda string
+		cambio float64
+	}{
+		{total: 100, moneda: "USD", cambio: 17},
+		{total: 1160, moneda: "MXN", cambio: 1},
+		{total: 50, moneda: "USD", cambio: 18},
+	}
+	for i, row := range rows {
+		cells := make([]Cell, 0, 29)
+		for j := 1; j <= 26; j++ {
+			cells = append(cells, Text(fmt.Sprintf("fila %d", i+1)))
+		}
+		cells = append(cells,
+			MoneyIn(row.total, row.moneda),
+			MoneyIn(row.total*row.cambio, "MXN"),
+			Int(i+1),
+		)
+		w.WriteRow(cells...)
+	}
+
+	w.WriteTotals("Total")
+}
+
+func TestSheetFileTotals(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "totales.xlsx")
+
+	file := NewFile(path)
+	writeTotalsBook(t, file)
+	if file.Err != nil {
+		t.Fatal(file.Err)
+	}
+	if err := file.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	book, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet := book.GetSheetName(0)
+
+	headers := map[string]string{
+		"A1":  "Texto 1",
+		"Z1":  "Texto 26",
+		"AA1": "Total",
+		"AB1": "Total MXN",
+		"AC1": "Conceptos",
+		"A5":  "Total",
+	}
+	for axis, want := range headers {
+		got, err := book.GetCellValue(sheet, axis)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%s = %q, se esperaba %q", axis, got, want)
+		}
+	}
+
+	formulas := map[string]string{
+		//Dólares y pesos en la misma columna no se suman
+		"AA5": "",
+		"AB5": "SUM(AB2:AB4)",
+		"AC5": "SUM(AC2:AC4)",
+	}
+	for axis, want := range formulas {
+		got, err := book.GetCellFormula(sheet, axis)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("fórmula de %s = %q, se esperaba %q", axis, got, want)
+		}
+	}
+}
+
+func TestStreamFileTotals(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "totales.xlsx")
+
+	file, err := NewWriter(path, FormatXLSX, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTotalsBook(t, file)
+	if err := file.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	book, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet := book.GetSheetName(0)
+
+	formulas := map[string]string{
+		"AA5": "",
+		"AB5": "SUM(AB2:AB4)",
+		"AC5": "SUM(AC2:AC4)",
+	}
+	for axis, want := range formulas {
+		got, err := book.GetCellFormula(sheet, axis)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("fórmula de %s = %q, se esperaba %q", axis, got, want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package sheet
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Format es el tipo de archivo de una exportación
+type Format string
+
+const (
+	FormatXLSX  Format = "xlsx"
+	FormatCSV   Format = "csv"
+	FormatTSV   Format = "tsv"
+	FormatJSON  Format = "json"
+	FormatJSONL Format = "jsonl"
+)
+
+// Formats son los formatos que se pueden exportar
+var Formats = []Format{FormatXLSX, FormatCSV, FormatTSV, FormatJSON, FormatJSONL}
+
+// Options son los ajustes de cada formato, los que no aplican se ignoran
+type Options struct {
+	// Delimiter separa las columnas en CSV, si es 0 se usa coma. TSV siempre usa tabulador
+	Delimiter rune
+	// BOM agrega la marca UTF-8 al inicio del CSV para que excel en español respete los acentos
+	BOM bool
+	// Sheet es el nombre de la hoja en xlsx
+	Sheet string
+	// Widths es el ancho de cada columna en xlsx
+	Widths []float64
+	// Keys son los nombres de los campos en JSON, si no se indican se usan los encabezados
+	Keys []string
+}
+
+// Writer es un archivo de exportación en cualquier formato
+type Writer interface {
+	RowWriter
+	Save() error
+}
+
+// ParseFormat valida el nombre de un formato
+func ParseFormat(name string) (Format, error) {
+	name = strings.ToLower(strings.TrimPrefix(name, "."))
+	for _, format := range Formats {
+		if string(format) == name {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("formato %q no soportado, usa %s", name, formatList())
+}
+
+// FormatFromPath regresa el formato según la extensión del archivo
+func FormatFromPath(path string) (Format, error) {
+	return ParseFormat(filepath.Ext(path))
+}
+
+func formatList() string {
+	names := make([]string, 0, len(Formats))
+	for _, format := range Formats {
+		names = append(names, string(format))
+	}
+	return strings.Join(names, ", ")
+}
+
+// NewWriter crea el archivo en el formato indicado, todos los formatos
+// escriben fila por fila así que sirven para exportaciones grandes
+func NewWriter(path string, format Format, opts Options) (Writer, error) {
+	switch format {
+	case FormatXLSX:
+		sheet := opts.Sheet
+		if sheet == "" {
+			sheet = "Sheet1"
+		}
+		file := NewStreamFile(path, sheet, opts.Widths)
+		return file, file.Err
+	case FormatCSV:
+		return newCSVFile(path, opts.Delimiter, opts.BOM)
+	case FormatTSV:
+		return newCSVFile(path, '\t', opts.BOM)
+	case FormatJSON:
+		return newJSONFile(path, false, opts.Keys)
+	case FormatJSONL:
+		return newJSONFile(path, true, opts.Keys)
+	}
+	return nil, fmt.Errorf("formato %q no soportado, usa %s", format, formatList())
+}
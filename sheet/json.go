@@ -0,0 +1,132 @@
+package sheet
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// JSONFile escribe cada fila como un objeto con los encabezados como campos,
+// ya sea en un arreglo (JSON) o un objeto por línea (JSON Lines)
+type JSONFile struct {
+	Err error
+
+	lines  bool
+	keys   []string
+	rows   int
+	file   *os.File
+	buffer *bufio.Writer
+}
+
+func newJSONFile(path string, lines bool, keys []string) (*JSONFile, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &JSONFile{
+		lines:  lines,
+		keys:   keys,
+		file:   file,
+		buffer: bufio.NewWriter(file),
+	}
+
+	if !lines {
+		_, j.Err = j.buffer.WriteString("[")
+	}
+
+	return j, nil
+}
+
+// WriteHeader guarda los nombres de los campos, si ya se indicaron en las opciones se conservan
+func (j *JSONFile) WriteHeader(titles ...string) {
+	if len(j.keys) == 0 {
+		j.keys = titles
+	}
+}
+
+// WriteRow escribe un objeto con los campos en el orden de las columnas
+func (j *JSONFile) WriteRow(cells ...Cell) {
+	if j.Err != nil {
+		return
+	}
+
+	//JSON Lines separa con salto de línea, el arreglo con coma y salto de línea
+	switch {
+	case j.rows > 0 && j.lines:
+		j.write("\n")
+	case j.rows > 0:
+		j.write(",\n")
+	case !j.lines:
+		j.write("\n")
+	}
+	j.write("{")
+
+	for i, cell := range cells {
+		if i > 0 {
+			j.write(",")
+		}
+
+		key := ""
+		if i < len(j.keys) {
+			key = j.keys[i]
+		}
+		j.writeJSON(key)
+		j.write(":")
+		j.writeJSON(cellJSON(cell))
+	}
+
+	j.write("}")
+	j.rows++
+}
+
+// WriteTotals no escribe nada, en JSON los totales se calculan al leer los datos
+func (j *JSONFile) WriteTotals(label string) {}
+
+func (j *JSONFile) write(text string) {
+	if j.Err != nil {
+		return
+	}
+	_, j.Err = j.buffer.WriteString(text)
+}
+
+func (j *JSONFile) writeJSON(value interface{}) {
+	if j.Err != nil {
+		return
+	}
+	var content []byte
+	if content, j.Err = json.Marshal(value); j.Err == nil {
+		_, j.Err = j.buffer.Write(content)
+	}
+}
+
+// Save cierra el arreglo si hace falta y cierra el archivo
+func (j *JSONFile) Save() error {
+	defer j.file.Close()
+
+	if j.lines {
+		if j.rows > 0 {
+			j.write("\n")
+		}
+	} else {
+		j.write("\n]\n")
+	}
+
+	if j.Err != nil {
+		return j.Err
+	}
+	if err := j.buffer.Flush(); err != nil {
+		return err
+	}
+
+	return j.file.Close()
+}
+
+// cellJSON regresa los importes y cantidades como números y las fechas como texto
+func cellJSON(cell Cell) interface{} {
+	if cell.Kind == DateCell {
+		return cell.Value.(time.Time).Format(textDateFormat)
+	}
+	return cell.Value
+}
@@ -0,0 +1,216 @@
+package sheet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// StreamFile escribe una hoja fila por fila con el StreamWriter de excelize,
+// las filas no se guardan en memoria así que sirve para exportaciones muy
+// grandes. Tiene el mismo formato que SheetFile pero el ancho de las columnas
+// se indica al crearlo porque las filas ya escritas no se pueden revisar
+type StreamFile struct {
+	Err error
+
+	sheet     string
+	row       int
+	headerRow int
+	// lastColumn es la columna más a la derecha que se ha escrito
+	lastColumn int
+	// filtered indica que el autofiltro ya se puso al escribir los totales
+	filtered bool
+	// sums tiene el estilo de cada columna que se suma en la fila de totales
+	sums    map[int]int
+	monedas monedas
+	styles  styles
+	writer  *excelize.StreamWriter
+	*excelize.File
+}
+
+// NewStreamFile crea el libro con una hoja y el ancho de cada columna
+func NewStreamFile(path, sheet string, widths []float64) *StreamFile {
+	file := excelize.NewFile()
+	file.Path = path
+	file.SetSheetName("Sheet1", sheet)
+
+	s := &StreamFile{
+		sheet:   sheet,
+		row:     1,
+		sums:    map[int]int{},
+		monedas: monedas{},
+		File:    file,
+	}
+
+	if s.styles, s.Err = newStyles(file); s.Err != nil {
+		return s
+	}
+
+	//Los paneles se escriben al inicio de la hoja, se fijan antes de empezar
+	if s.Err = file.SetPanes(sheet, freezePanes(1)); s.Err != nil {
+		return s
+	}
+
+	if s.writer, s.Err = file.NewStreamWriter(sheet); s.Err != nil {
+		return s
+	}
+
+	for i, width := range widths {
+		if width > maxColumnWidth {
+			width = maxColumnWidth
+		}
+		if s.Err = s.writer.SetColWidth(i+1, i+1, width+cellPadding); s.Err != nil {
+			return s
+		}
+	}
+
+	return s
+}
+
+// WriteHeader escribe la fila de encabezados, debe ser la primera fila
+func (s *StreamFile) WriteHeader(titles ...string) {
+	values := make([]interface{}, 0, len(titles))
+	for _, title := range titles {
+		values = append(values, excelize.Cell{StyleID: s.styles.header, Value: title})
+	}
+
+	s.headerRow = s.row
+	s.setRow(values)
+}
+
+// WriteRow escribe la fila con el formato de cada celda
+func (s *StreamFile) WriteRow(cells ...Cell) {
+	values := make([]interface{}, 0, len(cells))
+	for i, cell := range cells {
+		style := 0
+		switch cell.Kind {
+		case MoneyCell:
+			style = s.styles.money
+			s.sums[i+1] = s.styles.totalMoney
+			s.monedas.add(i+1, cell.Moneda)
+		case IntCell:
+			style = s.styles.integer
+			s.sums[i+1] = s.styles.totalInteger
+		case DateCell:
+			style = s.styles.date
+		case LinkCell:
+			//El StreamWriter no escribe hipervínculos, se usa la fórmula HIPERVINCULO
+			if url := cell.Value.(string); url != "" {
+				values = append(values, excelize.Cell{StyleID: s.styles.link, Formula: hyperlinkFormula(url), Value: url})
+				continue
+			}
+		}
+		values = append(values, excelize.Cell{StyleID: style, Value: cell.Value})
+	}
+
+	s.setRow(values)
+}
+
+// WriteTotals escribe la etiqueta y la suma de cada columna de importes o
+// cantidades, sin sumar las que tienen importes en varias monedas. El
+// autofiltro abarca hasta la fila anterior
+func (s *StreamFile) WriteTotals(label string) {
+	if s.Err != nil {
+		return
+	}
+
+	s.Err = s.autoFilter()
+	s.filtered = true
+
+	columns := make([]int, 0, len(s.sums))
+	for column := range s.sums {
+		if !s.monedas.mixta(column) {
+			columns = append(columns, column)
+		}
+	}
+	sort.Ints(columns)
+
+	last := 1
+	if len(columns) > 0 {
+		last = columns[len(columns)-1]
+	}
+
+	values := make([]interface{}, last)
+	values[0] = excelize.Cell{StyleID: s.styles.totalLabel, Value: label}
+	for _, column := range columns {
+		name, err := excelize.ColumnNumberToName(column)
+		if err != nil {
+			s.Err = err
+			return
+		}
+		values[column-1] = excelize.Cell{
+			StyleID: s.sums[column],
+			Formula: fmt.Sprintf("SUM(%s%d:%s%d)", name, s.headerRow+1, name, s.row-1),
+		}
+	}
+
+	s.setRow(values)
+}
+
+// hyperlinkFormula arma la fórmula que abre la dirección y la muestra como texto
+func hyperlinkFormula(url string) string {
+	quoted := `"` + strings.ReplaceAll(url, `"`, `""`) + `"`
+	return "HYPERLINK(" + quoted + "," + quoted + ")"
+}
+
+func (s *StreamFile) setRow(values []interface{}) {
+	if s.Err != nil {
+		return
+	}
+
+	axis, err := excelize.CoordinatesToCellName(1, s.row)
+	if err != nil {
+		s.Err = err
+		return
+	}
+
+	if s.Err = s.writer.SetRow(axis, values); s.Err != nil {
+		return
+	}
+
+	if len(values) > s.lastColumn {
+		s.lastColumn = len(values)
+	}
+	s.row++
+}
+
+// autoFilter pone el autofiltro de los encabezados a la última fila escrita,
+// se agrega a la hoja antes de cerrar el StreamWriter
+func (s *StreamFile) autoFilter() error {
+	if s.headerRow == 0 || s.lastColumn == 0 {
+		return nil
+	}
+
+	first, err := excelize.CoordinatesToCellName(1, s.headerRow)
+	if err != nil {
+		return err
+	}
+	last, err := excelize.CoordinatesToCellName(s.lastColumn, s.row-1)
+	if err != nil {
+		return err
+	}
+
+	return s.AutoFilter(s.sheet, first, last, "")
+}
+
+// Save cierra la hoja y guarda el archivo, si no se escribieron totales el
+// autofiltro abarca todas las filas
+func (s *StreamFile) Save() error {
+	if s.Err != nil {
+		return s.Err
+	}
+
+	if !s.filtered {
+		if err := s.autoFilter(); err != nil {
+			return err
+		}
+	}
+
+	if err := s.writer.Flush(); err != nil {
+		return err
+	}
+
+	return s.File.Save()
+}
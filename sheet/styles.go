@@ -0,0 +1,74 @@
+package sheet
+
+import (
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	moneyFormat = `"$"#,##0.00`
+	dateFormat  = "dd/mm/yyyy hh:mm:ss"
+
+	// Espacio extra en el ancho de las columnas para el botón del autofiltro
+	cellPadding    = 3
+	maxColumnWidth = 60
+
+	headerColor = "1F4E78"
+	linkColor   = "0563C1"
+	totalColor  = "DDEBF7"
+)
+
+// styles son los estilos de celda que comparten todas las hojas
+type styles struct {
+	header       int
+	money        int
+	integer      int
+	date         int
+	link         int
+	totalLabel   int
+	totalMoney   int
+	totalInteger int
+}
+
+func newStyles(file *excelize.File) (styles, error) {
+	var s styles
+	var err error
+
+	money := moneyFormat
+	date := dateFormat
+	border := []excelize.Border{{Type: "top", Color: headerColor, Style: 1}}
+	totalFill := excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{totalColor}}
+
+	definitions := []struct {
+		id    *int
+		style *excelize.Style
+	}{
+		{&s.header, &excelize.Style{
+			Font:      &excelize.Font{Bold: true, Color: "FFFFFF"},
+			Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{headerColor}},
+			Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+		}},
+		{&s.money, &excelize.Style{CustomNumFmt: &money}},
+		{&s.integer, &excelize.Style{NumFmt: 3}},
+		{&s.date, &excelize.Style{CustomNumFmt: &date}},
+		{&s.link, &excelize.Style{Font: &excelize.Font{Color: linkColor, Underline: "single"}}},
+		{&s.totalLabel, &excelize.Style{Font: &excelize.Font{Bold: true}, Fill: totalFill, Border: border}},
+		{&s.totalMoney, &excelize.Style{Font: &excelize.Font{Bold: true}, Fill: totalFill, Border: border, CustomNumFmt: &money}},
+		{&s.totalInteger, &excelize.Style{Font: &excelize.Font{Bold: true}, Fill: totalFill, Border: border, NumFmt: 3}},
+	}
+
+	for _, d := range definitions {
+		if *d.id, err = file.NewStyle(d.style); err != nil {
+			return s, err
+		}
+	}
+
+	return s, nil
+}
+
+// freezePanes fija las filas hasta la de encabezados
+func freezePanes(headerRow int) string {
+	return fmt.Sprintf(`{"freeze":true,"split":false,"x_split":0,"y_split":%d,"top_left_cell":"A%d","active_pane":"bottomLeft","panes":[{"sqref":"A%d","active_cell":"A%d","pane":"bottomLeft"}]}`,
+		headerRow, headerRow+1, headerRow+1, headerRow+1)
+}
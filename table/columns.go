@@ -0,0 +1,247 @@
+package table
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/dannywolfmx/cfdi-xls/esquema"
+	"github.com/dannywolfmx/cfdi-xls/letra"
+	"github.com/dannywolfmx/cfdi-xls/sheet"
+)
+
+// cfdiColumn describe una columna que se puede mostrar en la tabla
+type cfdiColumn struct {
+	ID    string
+	Title string
+	Width int
+	Value func(complemento.CFDI) string
+	// Number se usa para ordenar las columnas de importes, si es nil se ordena por Value
+	Number func(complemento.CFDI) float64
+	// MXN indica que Number ya está convertido a pesos con el tipo de cambio
+	MXN bool
+	// SortNumber reemplaza a Number al ordenar, para comparar en pesos los
+	// importes en otra moneda
+	SortNumber func(complemento.CFDI) float64
+	// Date indica que Value es una fecha del CFDI, al exportar a excel se escribe como fecha
+	Date bool
+	// Link indica que Value es una dirección, al exportar a excel se escribe como hipervínculo
+	Link bool
+}
+
+var allColumns = []cfdiColumn{
+	{ID: "emisor", Title: "Emisor", Width: 40, Value: func(c complemento.CFDI) string { return c.Emisor.Nombre }},
+	{ID: "emisor_rfc", Title: "RFC emisor", Width: 14, Value: func(c complemento.CFDI) string { return c.Emisor.RFC }},
+	{ID: "receptor", Title: "Receptor", Width: 40, Value: func(c complemento.CFDI) string { return c.Receptor.Nombre }},
+	{ID: "receptor_rfc", Title: "RFC receptor", Width: 14, Value: func(c complemento.CFDI) string { return c.Receptor.RFC }},
+	{ID: "serie", Title: "Serie", Width: 8, Value: func(c complemento.CFDI) string { return c.Serie }},
+	{ID: "folio", Title: "Folio", Width: 10, Value: func(c complemento.CFDI) string { return c.Folio }},
+	{ID: "fecha", Title: "Fecha de emisión", Width: 20, Value: func(c complemento.CFDI) string { return c.Fecha }, Date: true},
+	{ID: "fecha_timbrado", Title: "Fecha de timbrado", Width: 20, Value: func(c complemento.CFDI) string { return c.Complemento.TimbreFiscalDigital.FechaTimbrado }, Date: true},
+	{ID: "tipo", Title: "Tipo", Width: 5, Value: func(c complemento.CFDI) string { return c.TipoDeComprobante }},
+	{ID: "metodo", Title: "Método", Width: 7, Value: func(c complemento.CFDI) string { return c.MetodoPago }},
+	{ID: "forma", Title: "Forma", Width: 6, Value: func(c complemento.CFDI) string { return c.FormaPago }},
+	{ID: "uso", Title: "Uso CFDI", Width: 9, Value: func(c complemento.CFDI) string { return c.Receptor.UsoCFDI }},
+	{ID: "subtotal", Title: "SubTotal", Width: 16, Value: func(c complemento.CFDI) string { return ac.FormatMoney(c.SubTotal) }, Number: func(c complemento.CFDI) float64 { return c.SubTotal }, SortNumber: func(c complemento.CFDI) float64 { return c.SubTotal * c.TipoDeCambio() }},
+	{ID: "descuento", Title: "Descuento", Width: 14, Value: func(c complemento.CFDI) string { return ac.FormatMoney(c.Descuento) }, Number: func(c complemento.CFDI) float64 { return c.Descuento }, SortNumber: func(c complemento.CFDI) float64 { return c.Descuento * c.TipoDeCambio() }},
+	{ID: "iva", Title: "IVA", Width: 14, Value: func(c complemento.CFDI) string { return ac.FormatMoney(c.IVA()) }, Number: func(c complemento.CFDI) float64 { return c.IVA() }, SortNumber: func(c complemento.CFDI) float64 { return c.IVA() * c.TipoDeCambio() }},
+	{ID: "total", Title: "Total", Width: 20, Value: func(c complemento.CFDI) string { return ac.FormatMoney(c.Total) }, Number: func(c complemento.CFDI) float64 { return c.Total }, SortNumber: func(c complemento.CFDI) float64 { return c.Total * c.TipoDeCambio() }},
+	{ID: "letra", Title: "Importe con letra", Width: 50, Value: func(c complemento.CFDI) string { return letra.Importe(c.Total, c.Moneda) }},
+	{ID: "moneda", Title: "Moneda", Width: 7, Value: func(c complemento.CFDI) string { return c.Moneda }},
+	{ID: "tipo_cambio", Title: "T. cambio", Width: 10, Value: func(c complemento.CFDI) string { return c.TipoCambio }},
+	{ID: "subtotal_mxn", Title: "SubTotal MXN", Width: 16, Value: func(c complemento.CFDI) string { return ac.FormatMoney(c.SubTotal * c.TipoDeCambio()) }, Number: func(c complemento.CFDI) float64 { return c.SubTotal * c.TipoDeCambio() }, MXN: true},
+	{ID: "descuento_mxn", Title: "Descuento MXN", Width: 14, Value: func(c complemento.CFDI) string { return ac.FormatMoney(c.Descuento * c.TipoDeCambio()) }, Number: func(c complemento.CFDI) float64 { return c.Descuento * c.TipoDeCambio() }, MXN: true},
+	{ID: "iva_mxn", Title: "IVA MXN", Width: 14, Value: func(c complemento.CFDI) string { return ac.FormatMoney(c.IVA() * c.TipoDeCambio()) }, Number: func(c complemento.CFDI) float64 { return c.IVA() * c.TipoDeCambio() }, MXN: true},
+	{ID: "total_mxn", Title: "Total MXN", Width: 20, Value: func(c complemento.CFDI) string { return ac.FormatMoney(c.Total * c.TipoDeCambio()) }, Number: func(c complemento.CFDI) float64 { return c.Total * c.TipoDeCambio() }, MXN: true},
+	{ID: "uuid", Title: "UUID", Width: 36, Value: func(c complemento.CFDI) string { return c.Complemento.TimbreFiscalDigital.UUID }},
+	{ID: "verificacion", Title: "Verificación SAT", Width: 40, Value: verificacionURL, Link: true},
+	{ID: "version", Title: "Versión", Width: 8, Value: func(c complemento.CFDI) string { return c.Version }},
+	{ID: "etiquetas", Title: "Etiquetas", Width: 24, Value: func(c complemento.CFDI) string { return strings.Join(cfdiTags(c), ", ") }},
+	{ID: "nota", Title: "Nota", Width: 30, Value: func(c complemento.CFDI) string { return cfdiNote(c) }},
+	{ID: "estatus", Title: "Estatus", Width: 13, Value: func(c complemento.CFDI) string { return cfdiEstatus(c).String() }},
+	{ID: "problemas", Title: "Problemas", Width: 30, Value: func(c complemento.CFDI) string { return problemasText(cfdiProblemas(c)) }},
+	{ID: "esquema", Title: "Esquema", Width: 14, Value: func(c complemento.CFDI) string { return esquema.Resumen(cfdiEsquema(c)) }},
+	{ID: "sello", Title: "Sello", Width: 30, Value: func(c complemento.CFDI) string { return cfdiSello(c).String() }},
+	{ID: "conciliacion", Title: "Conciliación", Width: 28, Value: func(c complemento.CFDI) string {
+		if status, ok := cfdiConciliacion(c); ok {
+			return status.String()
+		}
+		return ""
+	}},
+}
+
+// Columnas que siempre se incluyen al exportar, las etiquetas y la nota. La liga
+// para verificar la factura en el SAT solo se agrega en excel, en exportColumns
+var exportAlwaysColumns = []string{"etiquetas", "nota"}
+
+func cfdiTags(c complemento.CFDI) []string {
+	return tagStore.Get(c.Complemento.TimbreFiscalDigital.UUID).Tags
+}
+
+func cfdiNote(c complemento.CFDI) string {
+	return tagStore.Get(c.Complemento.TimbreFiscalDigital.UUID).Note
+}
+
+// exportColumns agrega a las columnas visibles las etiquetas y la nota. En
+// excel también la liga de verificación, que ahí es un hipervínculo
+func exportColumns(ids []string, format sheet.Format) []string {
+	always := exportAlwaysColumns
+	if format == sheet.FormatXLSX {
+		always = append(always[:len(always):len(always)], "verificacion")
+	}
+
+	columns := append([]string{}, ids...)
+	for _, id := range always {
+		if !containsString(columns, id) {
+			columns = append(columns, id)
+		}
+	}
+	return columns
+}
+
+// Columnas que se muestran cuando no hay un layout guardado
+var defaultColumns = []string{"emisor", "receptor", "fecha_timbrado", "total"}
+
+func findColumn(id string) (cfdiColumn, bool) {
+	for _, c := range allColumns {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return cfdiColumn{}, false
+}
+
+// visibleColumns regresa las definiciones de las columnas visibles en el orden del catálogo
+func visibleColumns(ids []string) []cfdiColumn {
+	visible := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		visible[id] = true
+	}
+
+	columns := make([]cfdiColumn, 0, len(ids))
+	for _, c := range allColumns {
+		if visible[c.ID] {
+			columns = append(columns, c)
+		}
+	}
+	return columns
+}
+
+// tableColumns transforma las columnas visibles en columnas de la tabla,
+// marcando la columna por la que se ordena
+func tableColumns(ids []string, sortColumn string, sortDesc bool) []table.Column {
+	columns := make([]table.Column, 0, len(ids)+1)
+	columns = append(columns, table.Column{Title: " ", Width: 1})
+	for _, c := range visibleColumns(ids) {
+		title := c.Title
+		if c.ID == sortColumn {
+			if sortDesc {
+				title += " ▼"
+			} else {
+				title += " ▲"
+			}
+		}
+		columns = append(columns, table.Column{Title: title, Width: c.Width})
+	}
+	return columns
+}
+
+// sortCFDIS ordena los cfdis por la columna indicada, si no hay columna se
+// conserva el orden de carga (por fecha)
+func sortCFDIS(cfdis []complemento.CFDI, sortColumn string, sortDesc bool) {
+	column, ok := findColumn(sortColumn)
+	if !ok {
+		return
+	}
+
+	less := func(i, j int) bool {
+		return lessText(column.Value(cfdis[i]), column.Value(cfdis[j]))
+	}
+	number := column.Number
+	if column.SortNumber != nil {
+		number = column.SortNumber
+	}
+	if number != nil {
+		less = func(i, j int) bool {
+			return number(cfdis[i]) < number(cfdis[j])
+		}
+	}
+
+	sort.SliceStable(cfdis, func(i, j int) bool {
+		if sortDesc {
+			return less(j, i)
+		}
+		return less(i, j)
+	})
+}
+
+// lessText compara sin mayúsculas, los folios y series que son números se
+// comparan como números para que 9 quede antes de 10
+func lessText(a, b string) bool {
+	na, errA := strconv.ParseInt(strings.TrimSpace(a), 10, 64)
+	nb, errB := strconv.ParseInt(strings.TrimSpace(b), 10, 64)
+	if errA == nil && errB == nil {
+		return na < nb
+	}
+	return strings.ToLower(a) < strings.ToLower(b)
+}
+
+// nextSortColumn avanza la columna de orden entre las columnas visibles,
+// después de la última se regresa al orden de carga
+func nextSortColumn(ids []string, sortColumn string) string {
+	columns := visibleColumns(ids)
+	if len(columns) == 0 {
+		return ""
+	}
+	if sortColumn == "" {
+		return columns[0].ID
+	}
+	for i, c := range columns {
+		if c.ID == sortColumn && i < len(columns)-1 {
+			return columns[i+1].ID
+		}
+	}
+	return ""
+}
+
+// toggleColumn muestra u oculta una columna, siempre queda al menos una visible
+func toggleColumn(ids []string, id string) []string {
+	result := make([]string, 0, len(ids))
+	found := false
+	for _, visible := range ids {
+		if visible == id {
+			found = true
+			continue
+		}
+		result = append(result, visible)
+	}
+
+	if !found {
+		return append(result, id)
+	}
+	if len(result) == 0 {
+		return ids
+	}
+	return result
+}
+
+func columnListView(ids []string) list.Model {
+	visible := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		visible[id] = true
+	}
+
+	items := []list.Item{}
+	for _, c := range allColumns {
+		if visible[c.ID] {
+			items = append(items, item{text: filterActiveStyle.Render("✓") + " " + c.Title})
+		} else {
+			items = append(items, item{text: filterInactiveStyle.Render("□") + " " + c.Title})
+		}
+	}
+
+	return newOptionList(items, "Columnas")
+}
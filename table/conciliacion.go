@@ -0,0 +1,91 @@
+package table
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/dannywolfmx/cfdi-xls/ledger"
+)
+
+// Prefijo de los filtros de conciliación para no chocar con las claves del SAT
+const conciliacionFilterPrefix = "conciliacion:"
+
+// Estado de conciliación de cada factura por cfdiKey, vacío si no se cargó la
+// contabilidad. Por UUID todas las facturas sin timbrar compartirían el estado
+var conciliacion = map[string]ledger.Status{}
+
+// Registros de la contabilidad que no tienen XML, no aparecen en la tabla
+var conciliacionSinXML []ledger.Match
+
+// SetConciliacion guarda el resultado de conciliar la contabilidad para
+// mostrarlo en la pestaña y la columna de conciliación
+func SetConciliacion(r ledger.Result) {
+	conciliacion = map[string]ledger.Status{}
+	conciliacionSinXML = nil
+
+	for _, m := range r.Matches {
+		if m.CFDI == nil {
+			conciliacionSinXML = append(conciliacionSinXML, m)
+			continue
+		}
+		conciliacion[cfdiKey(*m.CFDI)] = m.Status
+	}
+}
+
+func cfdiConciliacion(c complemento.CFDI) (ledger.Status, bool) {
+	status, ok := conciliacion[cfdiKey(c)]
+	return status, ok
+}
+
+func conciliacionFilterIDs(c complemento.CFDI) []string {
+	status, ok := cfdiConciliacion(c)
+	if !ok {
+		return nil
+	}
+	return []string{conciliacionFilterPrefix + strconv.Itoa(int(status))}
+}
+
+// conciliacionText regresa el nombre del estado de un filtro de conciliación
+func conciliacionText(key string) string {
+	status, err := strconv.Atoi(strings.TrimPrefix(key, conciliacionFilterPrefix))
+	if err != nil {
+		return key
+	}
+	return ledger.Status(status).String()
+}
+
+// conciliacionSinXMLItems muestra en la pestaña los registros sin XML, el
+// total y después cada registro con su fila, UUID o serie y folio, RFC e
+// importe. No se pueden filtrar porque no son facturas de la tabla
+func conciliacionSinXMLItems() []list.Item {
+	if len(conciliacionSinXML) == 0 {
+		return nil
+	}
+
+	importe := 0.0
+	for _, m := range conciliacionSinXML {
+		importe += m.Entry.Importe
+	}
+
+	items := make([]list.Item, 0, len(conciliacionSinXML)+1)
+	text := fmt.Sprintf("  %d registros de la contabilidad sin XML (%s)", len(conciliacionSinXML), ac.FormatMoney(importe))
+	items = append(items, item{text: text, disabled: true})
+
+	for _, m := range conciliacionSinXML {
+		e := m.Entry
+		id := e.UUID
+		if id == "" {
+			id = strings.TrimPrefix(e.Serie+"-"+e.Folio, "-")
+		}
+		text := fmt.Sprintf("    fila %d · %s", e.Row, id)
+		if e.RFC != "" {
+			text += " · " + e.RFC
+		}
+		text += " · " + ac.FormatMoney(e.Importe)
+		items = append(items, item{text: text, disabled: true})
+	}
+	return items
+}
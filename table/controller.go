@@ -2,65 +2,222 @@ package table
 
 import (
 	"fmt"
-	"log"
 	"os"
-	"os/exec"
-	"path/filepath"
+	"strings"
 
-	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/dannywolfmx/cfdi-xls/loader"
+	"github.com/dannywolfmx/cfdi-xls/tags"
 )
 
-func PrintTable(cfdi []complemento.CFDI) {
+// PrintTable muestra las facturas, si updates no es nil la tabla se actualiza
+// con los cambios de los directorios vigilados
+func PrintTable(cfdi []complemento.CFDI, store *tags.Store, updates <-chan loader.Update) {
 	originalCFDIS = cfdi
+	tagStore = store
+	registerFilterOptions()
 
-	columns := []table.Column{
-		{Title: "Emisor", Width: 40},
-		{Title: "Receptor", Width: 40},
-		{Title: "Fecha de timbrado", Width: 20},
-		{Title: "Importe pagado", Width: 20},
+	layout := loadLayout()
+
+	m := model{
+		focusState:   focusTable,
+		filter:       filterListView(0),
+		Tabs:         filterTabsTitles,
+		activeTab:    0,
+		columns:      layout.Columns,
+		sortColumn:   layout.SortColumn,
+		sortDesc:     layout.SortDesc,
+		columnPicker: columnListView(layout.Columns),
+		actions:      actionListView(),
+		marked:       map[string]bool{},
+		updates:      updates,
+		width:        defaultWidth,
+		height:       defaultHeight,
 	}
+	m.refreshTable()
+	m.applyLayout()
 
-	cfdi = filterCFDIS(cfdi)
+	if _, err := tea.NewProgram(m).Run(); err != nil {
+		fmt.Println("Error running program:", err)
+		os.Exit(1)
+	}
+}
 
-	rows := transformCFDIToRow(cfdi)
+// refreshTable vuelve a filtrar y ordenar las facturas y regenera la tabla
+func (m *model) refreshTable() {
+	//Copiar para no alterar el orden por fecha de originalCFDIS al ordenar
+	m.cfdis = append([]complemento.CFDI{}, filterCFDIS(originalCFDIS)...)
+	sortCFDIS(m.cfdis, m.sortColumn, m.sortDesc)
 
-	t := generateCFDITable(columns, rows)
+	columns := tableColumns(m.columns, m.sortColumn, m.sortDesc)
+	m.table = generateCFDITable(columns, transformCFDIToRow(m.cfdis, m.columns, m.marked))
 
-	textArea := ""
-	if len(cfdi) != 0 {
-		textArea = rowView(cfdi[0])
+	m.resumen = calcularResumen(m.cfdis)
+	m.resumenMarcadas = calcularResumen(m.markedCFDIS())
+	m.cur = 0
+}
+
+// saveLayout guarda las columnas y el orden actuales para la siguiente sesión
+func (m model) saveLayout() {
+	//Si no se puede guardar solo se pierde la preferencia, la tabla sigue funcionando
+	_ = saveLayout(tableLayout{
+		Columns:    m.columns,
+		SortColumn: m.sortColumn,
+		SortDesc:   m.sortDesc,
+	})
+}
+
+// updateColumnPicker maneja las teclas mientras el selector de columnas está abierto
+func (m model) updateColumnPicker(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "esc", "c":
+			m.focusState = focusTable
+			return m, nil
+		case " ":
+			selectedIndex := m.columnPicker.Index()
+
+			m.columns = toggleColumn(m.columns, allColumns[selectedIndex].ID)
+			if !containsString(m.columns, m.sortColumn) {
+				m.sortColumn = ""
+			}
+			m.refreshTable()
+			m.saveLayout()
+
+			m.columnPicker = columnListView(m.columns)
+			m.columnPicker.Select(selectedIndex)
+			m.applyLayout()
+			return m, nil
+		}
 	}
 
-	m := model{
-		table:      t,
-		textarea:   textArea,
-		cfdis:      cfdi,
-		focusState: focusTable,
-		filter:     filterListView(0),
-		resumen:    calcularResumen(cfdi),
-		Tabs:       filterTabsTitles,
-		activeTab:  0,
+	var cmd tea.Cmd
+	m.columnPicker, cmd = m.columnPicker.Update(msg)
+	m.applyLayout()
+	return m, cmd
+}
+
+// updateInspector maneja las teclas mientras el inspector está abierto
+func (m model) updateInspector(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.applyLayout()
+		return m, nil
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
 	}
 
-	if _, err := tea.NewProgram(m).Run(); err != nil {
-		fmt.Println("Error running program:", err)
-		os.Exit(1)
+	var cmd tea.Cmd
+	var open bool
+	m.inspector, open, cmd = m.inspector.Update(msg)
+	if !open {
+		m.focusState = focusTable
+		m.applyLayout()
+	}
+	return m, cmd
+}
+
+// refreshKeepingCursor vuelve a pintar la tabla conservando la factura seleccionada
+func (m *model) refreshKeepingCursor() {
+	selected := ""
+	if m.cur < len(m.cfdis) {
+		selected = cfdiKey(m.cfdis[m.cur])
+	}
+
+	m.refreshTable()
+
+	for i, c := range m.cfdis {
+		if cfdiKey(c) == selected {
+			m.table.SetCursor(i)
+			m.cur = i
+			return
+		}
+	}
+}
+
+// refreshAnnotations vuelve a pintar la tabla y los filtros después de cambiar
+// etiquetas o notas, conservando la fila seleccionada
+func (m *model) refreshAnnotations() {
+	m.refreshKeepingCursor()
+	m.filter = filterListView(m.activeTab)
+	m.setStatus("Anotación guardada", false)
+}
+
+// setStatus muestra un mensaje en la barra de estado
+func (m *model) setStatus(text string, isError bool) {
+	m.status = text
+	m.statusError = isError
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
 	}
+	return false
 }
 
 // Init
 func (m model) Init() tea.Cmd {
+	if m.updates != nil {
+		return waitForUpdate(m.updates)
+	}
 	return nil
 }
 
 // Update
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	//Los cambios de los directorios llegan sin importar qué panel tiene el foco
+	if update, ok := msg.(watchMsg); ok {
+		m.applyUpdate(loader.Update(update))
+		m.applyLayout()
+		return m, waitForUpdate(m.updates)
+	}
+
+	if m.focusState == focusColumns {
+		return m.updateColumnPicker(msg)
+	}
+
+	if m.focusState == focusInspector {
+		return m.updateInspector(msg)
+	}
+
+	if m.focusState == focusActions {
+		return m.updateActions(msg)
+	}
+
+	if m.focusState == focusPrompt {
+		return m.updatePrompt(msg)
+	}
+
+	if m.focusState == focusVerificacion {
+		return m.updateVerificacion(msg)
+	}
+
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case openResultMsg:
+		if msg.err != nil {
+			m.setStatus(msg.err.Error(), true)
+		} else {
+			m.setStatus("Abierto "+msg.path, false)
+		}
 	case tea.KeyMsg:
+		//Cualquier tecla da por visto el aviso de facturas nuevas
+		if m.nuevas > 0 {
+			m.nuevas = 0
+			m.applyLayout()
+		}
 		switch msg.String() {
 		case "esc":
 			if m.table.Focused() {
@@ -70,33 +227,176 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		//change focus
 		case "tab":
-			if m.focusState == focusTable {
+			if m.focusState == focusTable && !m.hideFilters {
 				m.focusState = focusFilter
 			} else {
 				m.focusState = focusTable
 			}
 		case "q", "ctrl+c":
 			return m, tea.Quit
+		//Show or hide panes
+		case "D":
+			m.hideDetail = !m.hideDetail
+		case "R":
+			m.hideResumen = !m.hideResumen
+		case "F":
+			m.hideFilters = !m.hideFilters
+			if m.hideFilters {
+				m.focusState = focusTable
+			}
+		//Columns
+		case "c":
+			m.focusState = focusColumns
+			m.columnPicker = columnListView(m.columns)
+			m.applyLayout()
+			return m, nil
+		//Sort
+		case "s":
+			if m.focusState == focusTable {
+				m.sortColumn = nextSortColumn(m.columns, m.sortColumn)
+				m.refreshTable()
+				m.saveLayout()
+				m.applyLayout()
+				return m, nil
+			}
+		case "S":
+			if m.focusState == focusTable {
+				if m.sortColumn == "" {
+					m.sortColumn = nextSortColumn(m.columns, m.sortColumn)
+				} else {
+					m.sortDesc = !m.sortDesc
+				}
+				m.refreshTable()
+				m.saveLayout()
+				m.applyLayout()
+				return m, nil
+			}
 		case "enter":
-			//Add the selected row to the textarea
-			if m.table.Focused() {
-				selectedRow := m.table.Cursor()
-
-				cfdi := m.cfdis[selectedRow]
-				//Open the file with the default program
-				cmd := exec.Command("cmd", "/c", "start", fmt.Sprintf("%s.xml", cfdi.Complemento.TimbreFiscalDigital.UUID))
-				//Obtener el directorio actual
-				ex, err := os.Executable()
+			//Open the XML with the default program
+			if m.focusState == focusTable && m.table.Focused() && m.cur < len(m.cfdis) {
+				cfdi := m.cfdis[m.cur]
+				m.setStatus("Abriendo "+cfdi.Path, false)
+				return m, openFile(cfdi.Path)
+			}
+		//Mark rows
+		case "x":
+			if m.focusState == focusTable && m.cur < len(m.cfdis) {
+				key := cfdiKey(m.cfdis[m.cur])
+				if m.marked[key] {
+					delete(m.marked, key)
+				} else {
+					m.marked[key] = true
+				}
+				m.refreshMarks()
+				return m, nil
+			}
+		case "a":
+			if m.focusState == focusTable {
+				m.markVisible(func(bool) bool { return true })
+				return m, nil
+			}
+		case "v":
+			if m.focusState == focusTable {
+				m.markVisible(func(marked bool) bool { return !marked })
+				return m, nil
+			}
+		case "X":
+			if m.focusState == focusTable {
+				m.marked = map[string]bool{}
+				m.refreshMarks()
+				return m, nil
+			}
+		case "e":
+			if m.focusState == focusTable {
+				if len(m.marked) == 0 {
+					m.setStatus("Marca facturas con x (o todas con a) para aplicarles una acción", true)
+					return m, nil
+				}
+				m.focusState = focusActions
+				m.actions = actionListView()
+				m.applyLayout()
+				return m, nil
+			}
+		//Export the filtered and sorted rows as they are on screen
+		case "E":
+			if m.focusState == focusTable {
+				return m, m.askExportView()
+			}
+		//Tags and notes of the selected row
+		case "t":
+			if m.focusState == focusTable && m.cur < len(m.cfdis) {
+				uuid := m.cfdis[m.cur].Complemento.TimbreFiscalDigital.UUID
+				if uuid == "" {
+					m.setStatus(tags.ErrSinUUID.Error(), true)
+					return m, nil
+				}
+				current := strings.Join(tagStore.Get(uuid).Tags, ", ")
+				return m, m.askText("Etiquetas (separadas por coma): ", current, func(m *model, value string) {
+					if err := tagStore.SetTags(uuid, tags.ParseTags(value)); err != nil {
+						m.setStatus(err.Error(), true)
+						return
+					}
+					m.refreshAnnotations()
+				})
+			}
+		case "n":
+			if m.focusState == focusTable && m.cur < len(m.cfdis) {
+				uuid := m.cfdis[m.cur].Complemento.TimbreFiscalDigital.UUID
+				if uuid == "" {
+					m.setStatus(tags.ErrSinUUID.Error(), true)
+					return m, nil
+				}
+				return m, m.askText("Nota: ", tagStore.Get(uuid).Note, func(m *model, value string) {
+					if err := tagStore.SetNote(uuid, value); err != nil {
+						m.setStatus(err.Error(), true)
+						return
+					}
+					m.refreshAnnotations()
+				})
+			}
+		case "i":
+			//Inspect the XML of the selected row
+			if m.focusState == focusTable && m.cur < len(m.cfdis) {
+				inspector, err := newInspector(m.cfdis[m.cur].Path)
 				if err != nil {
-					log.Fatal("Error al detectar el path del ejecutable")
+					m.setStatus(err.Error(), true)
+					return m, nil
 				}
-
-				path := filepath.Dir(ex)
-				cmd.Dir = fmt.Sprintf("%s\\cfdis", path)
-				err = cmd.Run()
+				m.inspector = inspector
+				m.focusState = focusInspector
+				m.applyLayout()
+				return m, nil
+			}
+		case "p":
+			//Open the PDF next to the XML if there is one
+			if m.focusState == focusTable && m.table.Focused() && m.cur < len(m.cfdis) {
+				//Se revisa de nuevo por si el PDF se agregó después de pintar el detalle
+				pdf, ok := pdfPath(m.cfdis[m.cur].Path)
+				pdfCache[m.cfdis[m.cur].Path] = pdf
+				if !ok {
+					m.setStatus("No hay un PDF junto al XML de esta factura", true)
+					return m, nil
+				}
+				m.setStatus("Abriendo "+pdf, false)
+				return m, openFile(pdf)
+			}
+		//Generate the printable PDF of the CFDI and open it
+		case "P":
+			if m.focusState == focusTable && m.table.Focused() && m.cur < len(m.cfdis) {
+				cfdi := m.cfdis[m.cur]
+				m.setStatus("Generando "+impresaPath(cfdi.Path), false)
+				return m, generatePDF(cfdi)
+			}
+		//Show the SAT verification QR of the selected row
+		case "V":
+			if m.focusState == focusTable && m.cur < len(m.cfdis) {
+				v, err := newVerificacion(m.cfdis[m.cur])
 				if err != nil {
-					log.Fatal(err)
+					m.setStatus(err.Error(), true)
+					return m, nil
 				}
+				m.verificacion = v
+				m.focusState = focusVerificacion
 				return m, nil
 			}
 		//Filter
@@ -104,36 +404,23 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.focusState == focusFilter {
 				selectedIndex := m.filter.Index()
 
-				selectedFilter := filterTabsContent[m.activeTab][selectedIndex]
+				//Las filas informativas al final de la lista no son filtros
+				options := filterTabOptions(m.activeTab)
+				if selectedIndex >= len(options) {
+					break
+				}
+				selectedFilter := options[selectedIndex]
 				if _, ok := activeFilters[selectedFilter]; ok {
 					delete(activeFilters, selectedFilter)
 				} else {
-					activeFilters[selectedFilter] = listFilters[selectedFilter]
+					activeFilters[selectedFilter] = filterOption(m.activeTab, selectedFilter)
 				}
 
-				m.cfdis = filterCFDIS(originalCFDIS)
-				m.table = generateCFDITable(m.table.Columns(), transformCFDIToRow(m.cfdis))
+				//Update table and resumen
+				m.refreshTable()
 
 				m.filter = filterListView(m.activeTab)
 				m.filter.Select(selectedIndex)
-				//Update resumen
-				m.resumen = calcularResumen(m.cfdis)
-				m.cur = 0
-			}
-		case "up", "k":
-			if m.focusState == focusTable {
-				if m.cur > 0 && m.table.Focused() {
-					m.cur--
-					m.textarea = rowView(m.cfdis[m.cur])
-				}
-			}
-		case "down", "j":
-			if m.focusState == focusTable {
-				if m.cur < len(m.cfdis)-1 && m.table.Focused() {
-					m.cur++
-					m.textarea = rowView(m.cfdis[m.cur])
-				}
-
 			}
 		case "left", "h":
 			if m.focusState == focusFilter {
@@ -162,6 +449,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if m.focusState == focusTable {
 		m.table, cmd = m.table.Update(msg)
 		cmds = append(cmds, cmd)
+		//Keep the detail in sync with every table movement (pgdown, g, G, etc.)
+		m.cur = m.table.Cursor()
 	}
 
 	if m.focusState == focusFilter {
@@ -169,5 +458,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 	}
 
+	//Los paneles pudieron cambiar de contenido o de tamaño
+	m.applyLayout()
+
 	return m, tea.Batch(cmds...)
 }
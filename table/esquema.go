@@ -0,0 +1,72 @@
+package table
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/dannywolfmx/cfdi-xls/esquema"
+	"github.com/dannywolfmx/cfdi-xls/sheet"
+)
+
+// Hoja con los errores de esquema de las facturas exportadas
+const reportSheetValidacion = "Validación"
+
+// Cuántos errores de esquema se muestran en el detalle de la factura
+const detailViolations = 3
+
+// Errores de esquema de cada archivo, validar vuelve a leer el XML
+var esquemaCache = map[string][]esquema.Violation{}
+
+func cfdiEsquema(c complemento.CFDI) []esquema.Violation {
+	if violations, ok := esquemaCache[c.Path]; ok {
+		return violations
+	}
+
+	violations := validarEsquema(c)
+	esquemaCache[c.Path] = violations
+	return violations
+}
+
+// validarEsquema valida el XML sin guardar el resultado, un archivo que no se
+// puede leer es un error en la raíz
+func validarEsquema(c complemento.CFDI) []esquema.Violation {
+	violations, err := esquema.ValidateFile(c.Path)
+	if err != nil {
+		return []esquema.Violation{{XPath: "/", Mensaje: err.Error()}}
+	}
+	return violations
+}
+
+// esquemaSection muestra los primeros errores de esquema en el detalle
+func esquemaSection(c complemento.CFDI) string {
+	violations := cfdiEsquema(c)
+	if len(violations) == 0 {
+		return ""
+	}
+
+	lines := []string{labelStyle.Render("Esquema:") + warningStyle.Render(" "+esquema.Resumen(violations))}
+	for i, v := range violations {
+		if i == detailViolations {
+			lines = append(lines, valueStyle.Render(fmt.Sprintf("... y %d más", len(violations)-detailViolations)))
+			break
+		}
+		lines = append(lines, valueStyle.Render(v.String()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// writeValidationSheet escribe un renglón por cada error de esquema de las facturas
+func writeValidationSheet(file sheet.RowWriter, cfdis []complemento.CFDI) {
+	file.WriteHeader("UUID", "Archivo", "XPath", "Error")
+	for _, c := range cfdis {
+		for _, v := range cfdiEsquema(c) {
+			file.WriteRow(
+				sheet.Text(c.Complemento.TimbreFiscalDigital.UUID),
+				sheet.Text(c.Path),
+				sheet.Text(v.XPath),
+				sheet.Text(v.Mensaje),
+			)
+		}
+	}
+}
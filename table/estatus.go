@@ -0,0 +1,55 @@
+package table
+
+import (
+	"strings"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/dannywolfmx/cfdi-xls/estatus"
+)
+
+// Prefijo de los filtros de estatus para no chocar con las claves del SAT
+const estatusFilterPrefix = "estatus:"
+
+// Último estado consultado de cada factura, nil si no hay archivo de estados
+var estatusCache *estatus.Cache
+
+// Con incluirCanceladas el resumen suma también las facturas canceladas
+var incluirCanceladas bool
+
+// SetEstatus guarda los estados consultados para la columna, la pestaña de
+// estatus y el resumen
+func SetEstatus(cache *estatus.Cache, canceladas bool) {
+	estatusCache = cache
+	incluirCanceladas = canceladas
+}
+
+func cfdiEstatus(c complemento.CFDI) estatus.Estado {
+	r, _ := estatusCache.Get(c.Complemento.TimbreFiscalDigital.UUID)
+	return r.Estado
+}
+
+// cancelada indica si la factura se deja fuera del resumen
+func cancelada(c complemento.CFDI) bool {
+	return !incluirCanceladas && cfdiEstatus(c) == estatus.Cancelado
+}
+
+func estatusFilterID(e estatus.Estado) string {
+	return estatusFilterPrefix + strings.ToLower(e.String())
+}
+
+func estatusFilterIDs(c complemento.CFDI) []string {
+	e := cfdiEstatus(c)
+	if e == estatus.Desconocido {
+		return nil
+	}
+	return []string{estatusFilterID(e)}
+}
+
+// estatusText regresa el nombre del estado de un filtro de estatus
+func estatusText(key string) string {
+	e, err := estatus.ParseEstado(strings.TrimPrefix(key, estatusFilterPrefix))
+	if err != nil {
+		return key
+	}
+	return e.String()
+}
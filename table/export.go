@@ -0,0 +1,170 @@
+package table
+
+import (
+	"time"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/dannywolfmx/cfdi-xls/esquema"
+	"github.com/dannywolfmx/cfdi-xls/sheet"
+	"github.com/dannywolfmx/cfdi-xls/tags"
+)
+
+// exportXLSX escribe las facturas con las columnas visibles en un archivo de excel
+func exportXLSX(path string, cfdis []complemento.CFDI, columns []string) error {
+	file := sheet.NewFile(path)
+	file.RenameSheet(reportSheetFacturas)
+	writeDetailSheet(file, sheet.FormatXLSX, cfdis, columns)
+
+	file.AddSheet(reportSheetValidacion)
+	writeValidationSheet(file, cfdis)
+
+	if file.Err != nil {
+		return file.Err
+	}
+
+	return file.Save()
+}
+
+// Opciones de CSV que se indicaron al iniciar, se usan en todas las exportaciones
+var exportOptions sheet.Options
+
+// SetExportOptions cambia el delimitador y la marca BOM de los CSV exportados
+func SetExportOptions(opts sheet.Options) {
+	exportOptions = opts
+}
+
+// detailOptions agrega a las opciones el ancho de las columnas para excel y
+// los identificadores de las columnas como campos de JSON
+func detailOptions(visible []cfdiColumn) sheet.Options {
+	opts := exportOptions
+	opts.Sheet = reportSheetFacturas
+	opts.Widths = make([]float64, 0, len(visible))
+	opts.Keys = make([]string, 0, len(visible))
+	for _, column := range visible {
+		opts.Widths = append(opts.Widths, float64(column.Width))
+		opts.Keys = append(opts.Keys, column.ID)
+	}
+	return opts
+}
+
+// ExportStream escribe en el formato indicado las facturas que entrega each
+// conforme se van leyendo, sin juntarlas en memoria. Usa las columnas
+// guardadas en el layout de la tabla
+func ExportStream(path string, format sheet.Format, store *tags.Store, each func(fn func(complemento.CFDI) error) error) error {
+	tagStore = store
+	visible := streamColumns(visibleColumns(exportColumns(loadLayout().Columns, format)))
+
+	file, err := sheet.NewWriter(path, format, detailOptions(visible))
+	if err != nil {
+		return err
+	}
+
+	file.WriteHeader(columnTitles(visible)...)
+
+	err = each(func(c complemento.CFDI) error {
+		file.WriteRow(columnCells(visible, c)...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	file.WriteTotals("Total")
+
+	return file.Save()
+}
+
+// streamColumns cambia las columnas que guardan su resultado por archivo por
+// versiones que no lo guardan, para que la memoria no crezca con la carpeta
+func streamColumns(columns []cfdiColumn) []cfdiColumn {
+	for i, column := range columns {
+		switch column.ID {
+		case "esquema":
+			columns[i].Value = func(c complemento.CFDI) string { return esquema.Resumen(validarEsquema(c)) }
+		case "sello":
+			columns[i].Value = func(c complemento.CFDI) string { return verifier.Verify(c).String() }
+		case "problemas":
+			columns[i].Value = func(c complemento.CFDI) string { return problemasText(reglasEngine.Check(c)) }
+		}
+	}
+	return columns
+}
+
+// writeDetailSheet escribe una fila por factura con las columnas visibles
+// y las que se exportan siempre en el formato
+func writeDetailSheet(file sheet.RowWriter, format sheet.Format, cfdis []complemento.CFDI, columns []string) {
+	visible := visibleColumns(exportColumns(columns, format))
+
+	file.WriteHeader(columnTitles(visible)...)
+	for _, c := range cfdis {
+		file.WriteRow(columnCells(visible, c)...)
+	}
+	file.WriteTotals("Total")
+}
+
+func columnTitles(columns []cfdiColumn) []string {
+	titles := make([]string, 0, len(columns))
+	for _, column := range columns {
+		titles = append(titles, column.Title)
+	}
+	return titles
+}
+
+// columnCells regresa los importes como números y las fechas como fechas
+// para que se puedan sumar y filtrar en excel
+func columnCells(columns []cfdiColumn, c complemento.CFDI) []sheet.Cell {
+	cells := make([]sheet.Cell, 0, len(columns))
+	for _, column := range columns {
+		cells = append(cells, columnCell(column, c))
+	}
+	return cells
+}
+
+func columnCell(column cfdiColumn, c complemento.CFDI) sheet.Cell {
+	if column.Number != nil {
+		if column.MXN {
+			return sheet.MoneyIn(column.Number(c), "MXN")
+		}
+		return sheet.MoneyIn(column.Number(c), monedaImporte(c))
+	}
+
+	if column.Date {
+		if fecha, err := time.Parse(complemento.FechaLayout, column.Value(c)); err == nil {
+			return sheet.Date(fecha)
+		}
+	}
+
+	if column.Link {
+		return sheet.Link(column.Value(c))
+	}
+
+	return sheet.Text(column.Value(c))
+}
+
+// exportFile escribe las facturas en el formato que indica la extensión del archivo
+func exportFile(path string, cfdis []complemento.CFDI, columns []string) error {
+	format, err := sheet.FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+
+	return writeExport(path, format, cfdis, columns)
+}
+
+// writeExport escribe las facturas en el formato indicado, excel se arma en
+// memoria para ajustar el ancho de las columnas a su contenido
+func writeExport(path string, format sheet.Format, cfdis []complemento.CFDI, columns []string) error {
+	if format == sheet.FormatXLSX {
+		return exportXLSX(path, cfdis, columns)
+	}
+
+	visible := visibleColumns(exportColumns(columns, format))
+	file, err := sheet.NewWriter(path, format, detailOptions(visible))
+	if err != nil {
+		return err
+	}
+
+	writeDetailSheet(file, format, cfdis, columns)
+
+	return file.Save()
+}
@@ -0,0 +1,200 @@
+package table
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/dannywolfmx/cfdi-xls/sheet"
+)
+
+// Hoja con el resumen y los filtros de la vista exportada
+const reportSheetResumen = "Resumen"
+
+// askExportView pide el archivo para exportar las facturas como se ven en la tabla
+func (m *model) askExportView() tea.Cmd {
+	path := m.exportPath
+	if path == "" {
+		path = "facturas.xlsx"
+	}
+	return m.askText("Exportar vista a (.xlsx, .csv, .tsv, .json, .jsonl): ", path, func(m *model, path string) {
+		m.confirmExportView(path)
+	})
+}
+
+// confirmExportView avisa si el archivo o su resumen ya existen antes de
+// sobrescribirlos
+func (m *model) confirmExportView(path string) {
+	if path == "" {
+		m.setStatus("No se indicó el archivo", true)
+		return
+	}
+	format, err := sheet.FormatFromPath(path)
+	if err != nil {
+		m.setStatus(err.Error(), true)
+		return
+	}
+
+	paths := []string{path}
+	if format != sheet.FormatXLSX {
+		paths = append(paths, resumenPath(path))
+	}
+
+	existing := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if _, err := os.Stat(p); err == nil {
+			existing = append(existing, p)
+		}
+	}
+
+	if len(existing) > 0 {
+		names := strings.Join(existing, " y ")
+		verb := "ya existe"
+		if len(existing) > 1 {
+			verb = "ya existen"
+		}
+		m.askText(fmt.Sprintf("%s %s, ¿sobrescribir? (s/n): ", names, verb), "", func(m *model, answer string) {
+			if !strings.EqualFold(answer, "s") {
+				m.setStatus("Exportación cancelada, no se modificó "+names, false)
+				return
+			}
+			m.exportView(path, true)
+		})
+		return
+	}
+
+	m.exportView(path, false)
+}
+
+func (m *model) exportView(path string, overwritten bool) {
+	m.exportPath = path
+
+	if err := exportView(path, m.cfdis, m.columns, m.resumen, m.sortColumn, m.sortDesc); err != nil {
+		m.setStatus(err.Error(), true)
+		return
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+
+	text := fmt.Sprintf("%d facturas exportadas a %s", len(m.cfdis), abs)
+	if format, _ := sheet.FormatFromPath(path); format != sheet.FormatXLSX {
+		text += ", resumen en " + resumenPath(abs)
+	}
+	if overwritten {
+		text += " (se sobrescribió el archivo anterior)"
+	}
+	m.setStatus(text, false)
+}
+
+// exportView escribe las facturas en el orden de la tabla con las columnas
+// visibles, junto con el resumen y los filtros activos. En excel el resumen
+// va en otra hoja y en los demás formatos en un archivo -resumen a un lado
+func exportView(path string, cfdis []complemento.CFDI, columns []string, r resumen, sortColumn string, sortDesc bool) error {
+	format, err := sheet.FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+
+	rows := viewResumenRows(r, sortColumn, sortDesc)
+
+	if format == sheet.FormatXLSX {
+		file := sheet.NewFile(path)
+		file.RenameSheet(reportSheetFacturas)
+		writeDetailSheet(file, format, cfdis, columns)
+
+		file.AddSheet(reportSheetResumen)
+		writeResumenRows(file, rows)
+
+		file.AddSheet(reportSheetValidacion)
+		writeValidationSheet(file, cfdis)
+
+		return file.Save()
+	}
+
+	if err := exportFile(path, cfdis, columns); err != nil {
+		return err
+	}
+
+	opts := exportOptions
+	opts.Keys = []string{"concepto", "valor"}
+	file, err := sheet.NewWriter(resumenPath(path), format, opts)
+	if err != nil {
+		return err
+	}
+	writeResumenRows(file, rows)
+
+	return file.Save()
+}
+
+// resumenPath regresa la ruta del resumen junto al archivo, facturas.csv -> facturas-resumen.csv
+func resumenPath(path string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-resumen" + ext
+}
+
+type resumenRow struct {
+	Concepto string
+	Valor    sheet.Cell
+}
+
+// viewResumenRows arma las filas con los totales, el orden y los filtros de la vista
+func viewResumenRows(r resumen, sortColumn string, sortDesc bool) []resumenRow {
+	rows := []resumenRow{
+		{"Facturas", sheet.Int(r.CantidadFacturas)},
+		{"SubTotal", sheet.Money(r.SubTotal)},
+		{"Descuento", sheet.Money(r.Descuento)},
+		{"IVA", sheet.Money(r.IVA)},
+		{"Total", sheet.Money(r.Total)},
+	}
+	if r.CantidadFacturas > 0 {
+		rows = append(rows, resumenRow{"Promedio", sheet.Money(r.Total / float64(r.CantidadFacturas))})
+	}
+	if r.Canceladas > 0 {
+		rows = append(rows, resumenRow{"Canceladas sin sumar", sheet.Int(r.Canceladas)})
+	}
+
+	orden := "Orden de carga"
+	if column, ok := findColumn(sortColumn); ok {
+		orden = column.Title + " ascendente"
+		if sortDesc {
+			orden = column.Title + " descendente"
+		}
+	}
+	rows = append(rows, resumenRow{"Orden", sheet.Text(orden)})
+
+	if _, ok := activeFilters[filterIgnoreFilter.ID]; ok {
+		return append(rows, resumenRow{"Filtros", sheet.Text(filterIgnoreFilter.Text)})
+	}
+
+	for tab, title := range filterTabsTitles {
+		active := make([]string, 0)
+		for _, id := range filterTabOptions(tab) {
+			if _, ok := activeFilters[id]; !ok {
+				continue
+			}
+			if textOnlyTab(tab) {
+				active = append(active, optionText(tab, id))
+			} else {
+				active = append(active, optionLabel(tab, id))
+			}
+		}
+		if len(active) > 0 {
+			rows = append(rows, resumenRow{"Filtro " + title, sheet.Text(strings.Join(active, ", "))})
+		}
+	}
+
+	return rows
+}
+
+func writeResumenRows(file sheet.RowWriter, rows []resumenRow) {
+	file.WriteHeader("Concepto", "Valor")
+	for _, row := range rows {
+		file.WriteRow(sheet.Text(row.Concepto), row.Valor)
+	}
+}
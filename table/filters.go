@@ -22,9 +22,7 @@ func NewMetodoPagoFilter(activeFilters map[string]cfdiFilterOption) *MetodoPagoF
 }
 
 func (f *MetodoPagoFilter) IsActive() bool {
-	_, pueActive := f.filters[filterPUE.ID]
-	_, ppdActive := f.filters[filterPPD.ID]
-	return pueActive || ppdActive
+	return hasActiveOption(f.filters, tabMetodoPago)
 }
 
 func (f *MetodoPagoFilter) Apply(cfdis []complemento.CFDI) []complemento.CFDI {
@@ -53,12 +51,7 @@ func NewFormaPagoFilter(activeFilters map[string]cfdiFilterOption) *FormaPagoFil
 }
 
 func (f *FormaPagoFilter) IsActive() bool {
-	for _, id := range listFilterFormaPago {
-		if _, active := f.filters[id]; active {
-			return true
-		}
-	}
-	return false
+	return hasActiveOption(f.filters, tabFormaPago)
 }
 
 func (f *FormaPagoFilter) Apply(cfdis []complemento.CFDI) []complemento.CFDI {
@@ -87,12 +80,7 @@ func NewUsoCFDIFilter(activeFilters map[string]cfdiFilterOption) *UsoCFDIFilter
 }
 
 func (f *UsoCFDIFilter) IsActive() bool {
-	for _, id := range listFilterUsoCFDI {
-		if _, active := f.filters[id]; active {
-			return true
-		}
-	}
-	return false
+	return hasActiveOption(f.filters, tabUsoCFDI)
 }
 
 func (f *UsoCFDIFilter) Apply(cfdis []complemento.CFDI) []complemento.CFDI {
@@ -121,12 +109,7 @@ func NewTipoComprobanteFilter(activeFilters map[string]cfdiFilterOption) *TipoCo
 }
 
 func (f *TipoComprobanteFilter) IsActive() bool {
-	for _, id := range listFilterTipoComprobante {
-		if _, active := f.filters[id]; active {
-			return true
-		}
-	}
-	return false
+	return hasActiveOption(f.filters, tabTipoComprobante)
 }
 
 func (f *TipoComprobanteFilter) Apply(cfdis []complemento.CFDI) []complemento.CFDI {
@@ -143,6 +126,134 @@ func (f *TipoComprobanteFilter) Apply(cfdis []complemento.CFDI) []complemento.CF
 	return result
 }
 
+// TagFilter implementa filtrado por etiquetas del usuario, basta con una etiqueta activa
+type TagFilter struct {
+	filters map[string]cfdiFilterOption
+}
+
+func NewTagFilter(activeFilters map[string]cfdiFilterOption) *TagFilter {
+	return &TagFilter{
+		filters: activeFilters,
+	}
+}
+
+func (f *TagFilter) IsActive() bool {
+	return hasActiveOption(f.filters, tabEtiquetas)
+}
+
+func (f *TagFilter) Apply(cfdis []complemento.CFDI) []complemento.CFDI {
+	if !f.IsActive() {
+		return cfdis
+	}
+
+	result := make([]complemento.CFDI, 0)
+	for _, c := range cfdis {
+		for _, id := range tagFilterIDs(c) {
+			if _, ok := f.filters[id]; ok {
+				result = append(result, c)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// ConciliacionFilter implementa filtrado por el estado de conciliación con la contabilidad
+type ConciliacionFilter struct {
+	filters map[string]cfdiFilterOption
+}
+
+func NewConciliacionFilter(activeFilters map[string]cfdiFilterOption) *ConciliacionFilter {
+	return &ConciliacionFilter{
+		filters: activeFilters,
+	}
+}
+
+func (f *ConciliacionFilter) IsActive() bool {
+	return hasActiveOption(f.filters, tabConciliacion)
+}
+
+func (f *ConciliacionFilter) Apply(cfdis []complemento.CFDI) []complemento.CFDI {
+	if !f.IsActive() {
+		return cfdis
+	}
+
+	result := make([]complemento.CFDI, 0)
+	for _, c := range cfdis {
+		for _, id := range conciliacionFilterIDs(c) {
+			if _, ok := f.filters[id]; ok {
+				result = append(result, c)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// ProblemaFilter implementa filtrado por las reglas de negocio que no cumple la factura
+type ProblemaFilter struct {
+	filters map[string]cfdiFilterOption
+}
+
+func NewProblemaFilter(activeFilters map[string]cfdiFilterOption) *ProblemaFilter {
+	return &ProblemaFilter{
+		filters: activeFilters,
+	}
+}
+
+func (f *ProblemaFilter) IsActive() bool {
+	return hasActiveOption(f.filters, tabProblemas)
+}
+
+func (f *ProblemaFilter) Apply(cfdis []complemento.CFDI) []complemento.CFDI {
+	if !f.IsActive() {
+		return cfdis
+	}
+
+	result := make([]complemento.CFDI, 0)
+	for _, c := range cfdis {
+		for _, id := range problemaFilterIDs(c) {
+			if _, ok := f.filters[id]; ok {
+				result = append(result, c)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// EstatusFilter implementa filtrado por el estado de la factura ante el SAT
+type EstatusFilter struct {
+	filters map[string]cfdiFilterOption
+}
+
+func NewEstatusFilter(activeFilters map[string]cfdiFilterOption) *EstatusFilter {
+	return &EstatusFilter{
+		filters: activeFilters,
+	}
+}
+
+func (f *EstatusFilter) IsActive() bool {
+	return hasActiveOption(f.filters, tabEstatus)
+}
+
+func (f *EstatusFilter) Apply(cfdis []complemento.CFDI) []complemento.CFDI {
+	if !f.IsActive() {
+		return cfdis
+	}
+
+	result := make([]complemento.CFDI, 0)
+	for _, c := range cfdis {
+		for _, id := range estatusFilterIDs(c) {
+			if _, ok := f.filters[id]; ok {
+				result = append(result, c)
+				break
+			}
+		}
+	}
+	return result
+}
+
 // FilterChain gestiona la cadena de filtros aplicados a los CFDIs
 type FilterChain struct {
 	filters []FilterStrategy
@@ -174,14 +285,56 @@ func FilterFactory(activeFilters map[string]cfdiFilterOption) *FilterChain {
 	}
 
 	chain := NewFilterChain()
-	chain.AddFilter(NewMetodoPagoFilter(activeFilters))
-	chain.AddFilter(NewFormaPagoFilter(activeFilters))
-	chain.AddFilter(NewUsoCFDIFilter(activeFilters))
-	chain.AddFilter(NewTipoComprobanteFilter(activeFilters))
+	for _, filter := range tabFilters(activeFilters) {
+		chain.AddFilter(filter)
+	}
+
+	return chain
+}
+
+// tabFilters devuelve un filtro por cada pestaña, en el mismo orden que filterTabsTitles
+func tabFilters(activeFilters map[string]cfdiFilterOption) []FilterStrategy {
+	return []FilterStrategy{
+		NewMetodoPagoFilter(activeFilters),
+		NewFormaPagoFilter(activeFilters),
+		NewUsoCFDIFilter(activeFilters),
+		NewTipoComprobanteFilter(activeFilters),
+		NewTagFilter(activeFilters),
+		NewConciliacionFilter(activeFilters),
+		NewProblemaFilter(activeFilters),
+		NewEstatusFilter(activeFilters),
+	}
+}
+
+// FacetFilterChain crea la cadena de filtros de todas las pestañas excepto skipTab,
+// para saber cuántos CFDIs seleccionaría cada opción de esa pestaña
+func FacetFilterChain(activeFilters map[string]cfdiFilterOption, skipTab int) *FilterChain {
+	if _, ok := activeFilters[filterIgnoreFilter.ID]; ok {
+		return NewFilterChain()
+	}
+
+	chain := NewFilterChain()
+	for i, filter := range tabFilters(activeFilters) {
+		if i == skipTab {
+			continue
+		}
+		chain.AddFilter(filter)
+	}
 
 	return chain
 }
 
+// hasActiveOption indica si alguna de las opciones seleccionadas es de la
+// pestaña, sin recorrer las facturas
+func hasActiveOption(filters map[string]cfdiFilterOption, tab int) bool {
+	for id := range filters {
+		if filterOptionTab(id) == tab {
+			return true
+		}
+	}
+	return false
+}
+
 // GenericFilterCFDIS es la nueva función genérica que reemplaza a filterCFDIS
 func GenericFilterCFDIS(cfdis []complemento.CFDI) []complemento.CFDI {
 	// Comprobar si hay filtros activos o si está activado el filtro de ignorar
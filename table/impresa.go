@@ -0,0 +1,51 @@
+package table
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/dannywolfmx/cfdi-xls/impresa"
+)
+
+// impresaPath es donde se genera la representación impresa de la factura,
+// junto al XML y sin reemplazar el PDF que haya mandado el emisor
+func impresaPath(xmlPath string) string {
+	return strings.TrimSuffix(xmlPath, filepath.Ext(xmlPath)) + ".impresa.pdf"
+}
+
+// generatePDF genera la representación impresa sin bloquear la interfaz y la abre
+func generatePDF(c complemento.CFDI) tea.Cmd {
+	return func() tea.Msg {
+		if c.Path == "" {
+			return openResultMsg{err: fmt.Errorf("no se conoce el archivo de la factura")}
+		}
+
+		path := impresaPath(c.Path)
+		if err := impresa.WriteFile(c, path); err != nil {
+			return openResultMsg{path: path, err: fmt.Errorf("no se pudo generar %s: %w", path, err)}
+		}
+		return openFile(path)()
+	}
+}
+
+// writePDFs escribe la representación impresa de cada factura en la carpeta
+func writePDFs(cfdis []complemento.CFDI, dir string) (int, error) {
+	if dir == "" {
+		return 0, fmt.Errorf("no se indicó la carpeta destino")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, err
+	}
+
+	nombres := impresa.Nombres{}
+	for i, c := range cfdis {
+		if err := impresa.WriteFile(c, filepath.Join(dir, nombres.FileName(c))); err != nil {
+			return i, err
+		}
+	}
+	return len(cfdis), nil
+}
@@ -0,0 +1,485 @@
+package table
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Estilos para resaltar la sintaxis del XML
+var (
+	xmlTagStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	xmlAttrNameStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("99"))
+	xmlAttrValueStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	xmlTextStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("15"))
+	xmlPunctStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	inspectorCursorStyle = lipgloss.NewStyle().Background(lipgloss.Color("57"))
+	inspectorMatchStyle  = lipgloss.NewStyle().Background(lipgloss.Color("58"))
+)
+
+// Con más atributos que este número se escribe un atributo por línea
+const inlineAttrs = 2
+
+type inspectorMode uint
+
+const (
+	inspectorXML inspectorMode = iota
+	inspectorTree
+)
+
+// xmlNode es un elemento del XML original con sus atributos e hijos
+type xmlNode struct {
+	name     string
+	attrs    []xml.Attr
+	text     string
+	children []*xmlNode
+	parent   *xmlNode
+	expanded bool
+}
+
+// inspectorLine es una línea ya formateada del inspector
+type inspectorLine struct {
+	node   *xmlNode
+	styled string
+	plain  string
+}
+
+// inspector muestra el XML de una factura formateado o como árbol de nodos
+type inspector struct {
+	path   string
+	root   *xmlNode
+	mode   inspectorMode
+	lines  []inspectorLine
+	cursor int
+	offset int
+	width  int
+	height int
+
+	search    textinput.Model
+	searching bool
+	query     string
+}
+
+func newInspector(path string) (inspector, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return inspector{}, err
+	}
+
+	root, err := parseXMLTree(content)
+	if err != nil {
+		return inspector{}, fmt.Errorf("no se pudo leer el XML %s: %w", path, err)
+	}
+
+	search := textinput.New()
+	search.Prompt = "/"
+	search.Placeholder = "buscar"
+
+	i := inspector{
+		path:   path,
+		root:   root,
+		mode:   inspectorTree,
+		search: search,
+	}
+	i.buildLines()
+
+	return i, nil
+}
+
+// parseXMLTree arma el árbol de nodos conservando los prefijos originales (cfdi:, tfd:)
+func parseXMLTree(content []byte) (*xmlNode, error) {
+	d := xml.NewDecoder(bytes.NewReader(content))
+
+	var root, current *xmlNode
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := xml.CopyToken(tok).(type) {
+		case xml.StartElement:
+			n := &xmlNode{name: qualifiedName(t.Name), attrs: t.Attr, parent: current}
+			if current == nil {
+				root = n
+			} else {
+				current.children = append(current.children, n)
+			}
+			current = n
+		case xml.EndElement:
+			if current != nil {
+				current = current.parent
+			}
+		case xml.CharData:
+			if current != nil {
+				current.text += strings.TrimSpace(string(t))
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("el archivo no contiene elementos")
+	}
+
+	//Solo el comprobante inicia abierto, Emisor, Receptor, Conceptos, etc. inician cerrados
+	root.expanded = true
+
+	return root, nil
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// buildLines genera las líneas del modo actual
+func (i *inspector) buildLines() {
+	i.lines = i.lines[:0]
+	if i.mode == inspectorXML {
+		i.xmlLines(i.root, 0)
+	} else {
+		i.treeLines(i.root, 0)
+	}
+
+	if i.cursor >= len(i.lines) {
+		i.cursor = len(i.lines) - 1
+	}
+	if i.cursor < 0 {
+		i.cursor = 0
+	}
+	i.scrollToCursor()
+}
+
+func (i *inspector) addLine(node *xmlNode, indent int, styled, plain string) {
+	pad := strings.Repeat("  ", indent)
+	i.lines = append(i.lines, inspectorLine{node: node, styled: pad + styled, plain: pad + plain})
+}
+
+// xmlLines escribe el nodo como XML con sangría y resaltado
+func (i *inspector) xmlLines(n *xmlNode, indent int) {
+	open := xmlPunctStyle.Render("<") + xmlTagStyle.Render(n.name)
+	openPlain := "<" + n.name
+
+	closing := ">"
+	if len(n.children) == 0 && n.text == "" {
+		closing = "/>"
+	}
+
+	if len(n.attrs) <= inlineAttrs {
+		for _, a := range n.attrs {
+			open += " " + styledAttr(a)
+			openPlain += " " + plainAttr(a)
+		}
+		if len(n.children) == 0 && n.text != "" {
+			end := "</" + n.name + ">"
+			i.addLine(n, indent,
+				open+xmlPunctStyle.Render(">")+xmlTextStyle.Render(n.text)+xmlPunctStyle.Render("</")+xmlTagStyle.Render(n.name)+xmlPunctStyle.Render(">"),
+				openPlain+">"+n.text+end)
+			return
+		}
+		i.addLine(n, indent, open+xmlPunctStyle.Render(closing), openPlain+closing)
+	} else {
+		i.addLine(n, indent, open, openPlain)
+		for idx, a := range n.attrs {
+			styled, plain := styledAttr(a), plainAttr(a)
+			if idx == len(n.attrs)-1 {
+				styled += xmlPunctStyle.Render(closing)
+				plain += closing
+			}
+			i.addLine(n, indent+2, styled, plain)
+		}
+	}
+
+	if closing == "/>" {
+		return
+	}
+
+	if n.text != "" {
+		i.addLine(n, indent+1, xmlTextStyle.Render(n.text), n.text)
+	}
+	for _, child := range n.children {
+		i.xmlLines(child, indent+1)
+	}
+	i.addLine(n, indent,
+		xmlPunctStyle.Render("</")+xmlTagStyle.Render(n.name)+xmlPunctStyle.Render(">"),
+		"</"+n.name+">")
+}
+
+func styledAttr(a xml.Attr) string {
+	return xmlAttrNameStyle.Render(qualifiedName(a.Name)) + xmlPunctStyle.Render("=") + xmlAttrValueStyle.Render(fmt.Sprintf("%q", a.Value))
+}
+
+func plainAttr(a xml.Attr) string {
+	return fmt.Sprintf("%s=%q", qualifiedName(a.Name), a.Value)
+}
+
+// treeLines escribe el nodo como árbol, los atributos solo se ven si el nodo está abierto
+func (i *inspector) treeLines(n *xmlNode, indent int) {
+	marker := "•"
+	if len(n.children) > 0 || len(n.attrs) > 0 || n.text != "" {
+		marker = "▸"
+		if n.expanded {
+			marker = "▾"
+		}
+	}
+
+	label := n.name
+	summary := ""
+	if !n.expanded && len(n.children) > 0 {
+		summary = fmt.Sprintf(" (%d)", len(n.children))
+	}
+	i.addLine(n, indent, marker+" "+xmlTagStyle.Render(label)+xmlPunctStyle.Render(summary), marker+" "+label+summary)
+
+	if !n.expanded {
+		return
+	}
+
+	for _, a := range n.attrs {
+		name := qualifiedName(a.Name)
+		i.addLine(n, indent+2,
+			xmlAttrNameStyle.Render(name)+xmlPunctStyle.Render(": ")+xmlAttrValueStyle.Render(a.Value),
+			name+": "+a.Value)
+	}
+	if n.text != "" {
+		i.addLine(n, indent+2, xmlTextStyle.Render(n.text), n.text)
+	}
+	for _, child := range n.children {
+		i.treeLines(child, indent+1)
+	}
+}
+
+// bodyHeight es el número de líneas disponibles debajo del título
+func (i inspector) bodyHeight() int {
+	h := i.height - 2
+	if h < 1 {
+		return 1
+	}
+	return h
+}
+
+func (i *inspector) scrollToCursor() {
+	if i.cursor < i.offset {
+		i.offset = i.cursor
+	}
+	if i.cursor >= i.offset+i.bodyHeight() {
+		i.offset = i.cursor - i.bodyHeight() + 1
+	}
+	if i.offset < 0 {
+		i.offset = 0
+	}
+}
+
+func (i *inspector) moveCursor(delta int) {
+	i.cursor += delta
+	if i.cursor >= len(i.lines) {
+		i.cursor = len(i.lines) - 1
+	}
+	if i.cursor < 0 {
+		i.cursor = 0
+	}
+	i.scrollToCursor()
+}
+
+// setExpanded abre o cierra el nodo bajo el cursor, en modo árbol
+func (i *inspector) setExpanded(expanded bool) {
+	if i.mode != inspectorTree || len(i.lines) == 0 {
+		return
+	}
+	n := i.lines[i.cursor].node
+	n.expanded = expanded
+	i.buildLines()
+	i.cursorToNode(n)
+}
+
+func (i *inspector) cursorToNode(n *xmlNode) {
+	for idx, line := range i.lines {
+		if line.node == n {
+			i.cursor = idx
+			i.scrollToCursor()
+			return
+		}
+	}
+}
+
+func (i inspector) matches(line inspectorLine) bool {
+	return i.query != "" && strings.Contains(strings.ToLower(line.plain), strings.ToLower(i.query))
+}
+
+// findNext mueve el cursor a la siguiente coincidencia de la búsqueda, en
+// modo árbol también busca dentro de los nodos cerrados y los abre
+func (i *inspector) findNext(forward bool) bool {
+	if i.query == "" {
+		return false
+	}
+
+	if i.mode == inspectorTree {
+		i.expandMatches(i.root)
+		i.buildLines()
+	}
+
+	total := len(i.lines)
+	for step := 1; step <= total; step++ {
+		idx := i.cursor + step
+		if !forward {
+			idx = i.cursor - step
+		}
+		idx = ((idx % total) + total) % total
+		if i.matches(i.lines[idx]) {
+			i.cursor = idx
+			i.scrollToCursor()
+			return true
+		}
+	}
+
+	return false
+}
+
+// expandMatches abre los ancestros de los nodos que coinciden con la búsqueda
+func (i *inspector) expandMatches(n *xmlNode) bool {
+	query := strings.ToLower(i.query)
+	found := strings.Contains(strings.ToLower(n.name), query) || strings.Contains(strings.ToLower(n.text), query)
+	for _, a := range n.attrs {
+		if strings.Contains(strings.ToLower(plainAttr(a)), query) {
+			found = true
+		}
+	}
+	if found && len(n.attrs) > 0 {
+		n.expanded = true
+	}
+
+	for _, child := range n.children {
+		if i.expandMatches(child) {
+			n.expanded = true
+			found = true
+		}
+	}
+
+	return found
+}
+
+// Update maneja las teclas del inspector, regresa false cuando se debe cerrar
+func (i inspector) Update(msg tea.Msg) (inspector, bool, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return i, true, nil
+	}
+
+	if i.searching {
+		switch key.String() {
+		case "enter":
+			i.searching = false
+			i.query = i.search.Value()
+			i.search.Blur()
+			i.findNext(true)
+			return i, true, nil
+		case "esc":
+			i.searching = false
+			i.search.Blur()
+			return i, true, nil
+		}
+		var cmd tea.Cmd
+		i.search, cmd = i.search.Update(msg)
+		return i, true, cmd
+	}
+
+	switch key.String() {
+	case "esc", "q", "i":
+		return i, false, nil
+	case "t":
+		current := i.lines[i.cursor].node
+		if i.mode == inspectorTree {
+			i.mode = inspectorXML
+		} else {
+			i.mode = inspectorTree
+		}
+		i.buildLines()
+		i.cursorToNode(current)
+	case "up", "k":
+		i.moveCursor(-1)
+	case "down", "j":
+		i.moveCursor(1)
+	case "pgup", "ctrl+u":
+		i.moveCursor(-i.bodyHeight())
+	case "pgdown", "ctrl+d":
+		i.moveCursor(i.bodyHeight())
+	case "g", "home":
+		i.moveCursor(-len(i.lines))
+	case "G", "end":
+		i.moveCursor(len(i.lines))
+	case "enter", " ":
+		if len(i.lines) > 0 {
+			i.setExpanded(!i.lines[i.cursor].node.expanded)
+		}
+	case "right", "l":
+		i.setExpanded(true)
+	case "left", "h":
+		i.setExpanded(false)
+	case "/":
+		i.searching = true
+		i.search.SetValue("")
+		return i, true, i.search.Focus()
+	case "n":
+		i.findNext(true)
+	case "N":
+		i.findNext(false)
+	}
+
+	return i, true, nil
+}
+
+// View muestra el título y las líneas visibles del inspector
+func (i inspector) View() string {
+	var doc strings.Builder
+
+	mode := "Árbol"
+	if i.mode == inspectorXML {
+		mode = "XML"
+	}
+	title := fmt.Sprintf("Inspector · %s · %s", mode, i.path)
+	if i.query != "" {
+		title += fmt.Sprintf(" · búsqueda: %q", i.query)
+	}
+	doc.WriteString(headerStyle.Width(i.width).MaxWidth(i.width).Render(title))
+	doc.WriteString("\n")
+
+	lineStyle := lipgloss.NewStyle().MaxWidth(i.width)
+	end := i.offset + i.bodyHeight()
+	if end > len(i.lines) {
+		end = len(i.lines)
+	}
+	for idx := i.offset; idx < end; idx++ {
+		line := i.lines[idx]
+		text := line.styled
+		if idx == i.cursor {
+			text = inspectorCursorStyle.Render(line.plain)
+		} else if i.matches(line) {
+			text = inspectorMatchStyle.Render(line.plain)
+		}
+		doc.WriteString(lineStyle.Render(text))
+		doc.WriteString("\n")
+	}
+	for idx := end - i.offset; idx < i.bodyHeight(); idx++ {
+		doc.WriteString("\n")
+	}
+
+	if i.searching {
+		doc.WriteString(i.search.View())
+	} else {
+		doc.WriteString(xmlPunctStyle.Render("t: XML/árbol • enter: abrir/cerrar nodo • /: buscar • n/N: siguiente/anterior • esc: cerrar"))
+	}
+
+	return doc.String()
+}
@@ -0,0 +1,80 @@
+package table
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// tableLayout guarda la configuración de columnas entre sesiones
+type tableLayout struct {
+	Columns    []string `json:"columns"`
+	SortColumn string   `json:"sort_column"`
+	SortDesc   bool     `json:"sort_desc"`
+}
+
+const layoutFileName = "layout.json"
+
+func layoutPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cfdi-xls", layoutFileName), nil
+}
+
+// loadLayout lee el layout guardado, si no existe o es inválido regresa el layout por defecto
+func loadLayout() tableLayout {
+	layout := tableLayout{Columns: defaultColumns}
+
+	path, err := layoutPath()
+	if err != nil {
+		return layout
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return layout
+	}
+
+	var saved tableLayout
+	if err := json.Unmarshal(content, &saved); err != nil {
+		return layout
+	}
+
+	//Descartar columnas que ya no existen
+	columns := make([]string, 0, len(saved.Columns))
+	for _, id := range saved.Columns {
+		if _, ok := findColumn(id); ok {
+			columns = append(columns, id)
+		}
+	}
+	if len(columns) == 0 {
+		return layout
+	}
+
+	saved.Columns = columns
+	if _, ok := findColumn(saved.SortColumn); !ok {
+		saved.SortColumn = ""
+	}
+
+	return saved
+}
+
+func saveLayout(layout tableLayout) error {
+	path, err := layoutPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(layout, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0o644)
+}
@@ -0,0 +1,92 @@
+package table
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/dannywolfmx/cfdi-xls/loader"
+	"github.com/dannywolfmx/cfdi-xls/sheet"
+)
+
+// Hoja del reporte con las facturas de la metadata que no tienen XML
+const reportSheetSinXML = "Sin XML"
+
+// Facturas de la metadata del SAT que no tienen XML en los directorios, las
+// vigentes y las canceladas
+var metadataSinXML []loader.Metadata
+
+// SetMetadataSinXML guarda las facturas de la metadata sin XML para mostrarlas
+// en la pestaña de estatus y en el reporte
+func SetMetadataSinXML(rows []loader.Metadata) {
+	metadataSinXML = rows
+}
+
+// metadataFound quita de las facturas sin XML la que acaba de llegar
+func metadataFound(c complemento.CFDI) {
+	uuid := strings.ToUpper(c.Complemento.TimbreFiscalDigital.UUID)
+	for i, m := range metadataSinXML {
+		if m.UUID == uuid {
+			metadataSinXML = append(metadataSinXML[:i:i], metadataSinXML[i+1:]...)
+			return
+		}
+	}
+}
+
+// metadataSinXMLItem muestra en la pestaña cuántas facturas de la metadata no
+// tienen XML, no se pueden filtrar porque no son facturas de la tabla. El monto
+// es solo de las vigentes
+func metadataSinXMLItem() (list.Item, bool) {
+	if len(metadataSinXML) == 0 {
+		return nil, false
+	}
+
+	monto := 0.0
+	canceladas := 0
+	for _, m := range metadataSinXML {
+		if m.Cancelado {
+			canceladas++
+			continue
+		}
+		monto += m.Monto
+	}
+	text := fmt.Sprintf("  %d facturas de la metadata sin XML (%s)", len(metadataSinXML), ac.FormatMoney(monto))
+	if canceladas > 0 {
+		text += fmt.Sprintf(", %d canceladas", canceladas)
+	}
+	return item{text: text, disabled: true}, true
+}
+
+// writeSinXMLSheet escribe las facturas sin XML, el monto de las canceladas va
+// en su propia columna para que no se sume con el de las vigentes
+func writeSinXMLSheet(file sheet.RowWriter, rows []loader.Metadata) {
+	file.WriteHeader("UUID", "RFC emisor", "Emisor", "RFC receptor", "Emisión", "Efecto", "Estatus", "Cancelación", "Monto", "Monto cancelado", "Archivo")
+	for _, m := range rows {
+		emision := sheet.Text(m.FechaEmision)
+		if fecha, err := time.Parse("2006-01-02 15:04:05", m.FechaEmision); err == nil {
+			emision = sheet.Date(fecha)
+		}
+
+		monto, cancelado := sheet.Money(m.Monto), sheet.Text("")
+		if m.Cancelado {
+			monto, cancelado = sheet.Text(""), sheet.Money(m.Monto)
+		}
+
+		file.WriteRow(
+			sheet.Text(m.UUID),
+			sheet.Text(m.RfcEmisor),
+			sheet.Text(m.NombreEmisor),
+			sheet.Text(m.RfcReceptor),
+			emision,
+			sheet.Text(m.Efecto),
+			sheet.Text(m.Estatus()),
+			sheet.Text(m.FechaCancelacion),
+			monto,
+			cancelado,
+			sheet.Text(m.Path),
+		)
+	}
+	file.WriteTotals("Total")
+}
@@ -3,10 +3,14 @@ package table
 import (
 	"fmt"
 	"log"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/dannywolfmx/cfdi-xls/estatus"
+	"github.com/dannywolfmx/cfdi-xls/tags"
 )
 
 var (
@@ -33,6 +37,10 @@ var (
 	filterTipoEgreso   = cfdiFilterOption{ID: "E", Text: "Egreso"}
 	filterTipoTraslado = cfdiFilterOption{ID: "T", Text: "Traslado"}
 	filterTipoPago     = cfdiFilterOption{ID: "P", Text: "Pago"}
+
+	//Estatus ante el SAT
+	filterEstatusVigente   = cfdiFilterOption{ID: estatusFilterID(estatus.Vigente), Text: estatus.Vigente.String()}
+	filterEstatusCancelado = cfdiFilterOption{ID: estatusFilterID(estatus.Cancelado), Text: estatus.Cancelado.String()}
 )
 
 var listFilters = map[string]cfdiFilterOption{
@@ -58,6 +66,9 @@ var listFilters = map[string]cfdiFilterOption{
 	filterTipoTraslado.ID: filterTipoTraslado,
 	filterTipoPago.ID:     filterTipoPago,
 
+	filterEstatusVigente.ID:   filterEstatusVigente,
+	filterEstatusCancelado.ID: filterEstatusCancelado,
+
 	filterIgnoreFilter.ID: filterIgnoreFilter,
 }
 
@@ -86,17 +97,40 @@ var listFilterTipoComprobante = []string{
 	filterTipoPago.ID,
 }
 
+var listFilterEstatus = []string{
+	filterEstatusVigente.ID,
+	filterEstatusCancelado.ID,
+}
+
 var listFilterUsoCFDI = []string{
 	filterUsoCFDIG01.ID,
 	filterUsoCFDIG02.ID,
 	filterUsoCFDIG03.ID,
 }
 
+const (
+	tabMetodoPago = iota
+	tabFormaPago
+	tabUsoCFDI
+	tabTipoComprobante
+	tabEtiquetas
+	tabConciliacion
+	tabProblemas
+	tabEstatus
+)
+
+// Prefijo de los filtros de etiquetas para no chocar con las claves del SAT
+const tagFilterPrefix = "tag:"
+
 var filterTabsTitles = []string{
 	"Metodo de pago",      //PUE, PPD
 	"Forma de pago",       //Efectivo, Transferencia, Tarjeta de credito, etc
 	"Uso CFDI",            //G01, G02, G03
 	"Tipo de comprobante", //I, E, T, P
+	"Etiquetas",           //Etiquetas del usuario
+	"Conciliación",        //Resultado de conciliar con la contabilidad
+	"Problemas",           //Reglas de negocio que no se cumplen
+	"Estatus",             //Vigente o cancelado ante el SAT
 }
 
 var filterTabsContent = [][]string{
@@ -104,21 +138,84 @@ var filterTabsContent = [][]string{
 	listFilterFormaPago,
 	listFilterUsoCFDI,
 	listFilterTipoComprobante,
+	{}, //Las etiquetas salen de las anotaciones del usuario
+	{}, //Los estados salen de la conciliación, si se cargó la contabilidad
+	{}, //Solo las reglas que falla alguna factura
+	listFilterEstatus,
+}
+
+// Valores del CFDI que evalúa cada pestaña de filtros, una factura puede tener varias etiquetas
+var filterTabsValues = []func(complemento.CFDI) []string{
+	func(c complemento.CFDI) []string { return []string{c.MetodoPago} },
+	func(c complemento.CFDI) []string { return []string{c.FormaPago} },
+	func(c complemento.CFDI) []string { return []string{c.Receptor.UsoCFDI} },
+	func(c complemento.CFDI) []string { return []string{c.TipoDeComprobante} },
+	tagFilterIDs,
+	conciliacionFilterIDs,
+	problemaFilterIDs,
+	estatusFilterIDs,
+}
+
+// Descripción para los códigos que no están en las listas fijas
+var filterTabsDescription = []func(string) string{
+	func(string) string { return "Sin descripción" },
+	func(key string) string { return catalogText(formaDePago, key) },
+	func(key string) string { return catalogText(usoCFDI, key) },
+	func(string) string { return "Sin descripción" },
+	func(key string) string { return strings.TrimPrefix(key, tagFilterPrefix) },
+	conciliacionText,
+	problemaText,
+	estatusText,
+}
+
+// textOnlyTab indica si las opciones de la pestaña se muestran sin su clave,
+// porque la clave es interna y no del catálogo del SAT
+func textOnlyTab(tab int) bool {
+	return tab == tabEtiquetas || tab == tabConciliacion || tab == tabProblemas || tab == tabEstatus
+}
+
+// Anotaciones (etiquetas y notas) de las facturas
+var tagStore *tags.Store
+
+func tagFilterIDs(c complemento.CFDI) []string {
+	ids := make([]string, 0)
+	for _, tag := range tagStore.Get(c.Complemento.TimbreFiscalDigital.UUID).Tags {
+		ids = append(ids, tagFilterPrefix+tag)
+	}
+	return ids
 }
 
 var activeFilters = map[string]cfdiFilterOption{}
 
 var originalCFDIS []complemento.CFDI = make([]complemento.CFDI, 0)
 
+// monedaImporte regresa la moneda de los importes de la factura para no sumar
+// monedas distintas al exportar. Los pagos usan XXX y sus importes son cero
+func monedaImporte(c complemento.CFDI) string {
+	switch moneda := strings.ToUpper(c.Moneda); moneda {
+	case "":
+		return "MXN"
+	case "XXX":
+		return ""
+	default:
+		return moneda
+	}
+}
+
 func calcularResumen(cfdis []complemento.CFDI) resumen {
 	var r resumen
 
-	r.CantidadFacturas = len(cfdis)
-
 	for _, c := range cfdis {
+		if cancelada(c) {
+			r.Canceladas++
+			continue
+		}
+		r.CantidadFacturas++
+
 		if c.TipoCambio == "" || c.TipoCambio == "1" {
 			r.Descuento += c.Descuento
 			r.SubTotal += c.SubTotal
+			r.IVA += c.IVA()
 			r.Total += c.Total
 		} else {
 			fTipoDeCambio, err := strconv.ParseFloat(c.TipoCambio, 64)
@@ -129,6 +226,7 @@ func calcularResumen(cfdis []complemento.CFDI) resumen {
 
 			r.Descuento += c.Descuento * fTipoDeCambio
 			r.SubTotal += c.SubTotal * fTipoDeCambio
+			r.IVA += c.IVA() * fTipoDeCambio
 			r.Total += c.Total * fTipoDeCambio
 		}
 	}
@@ -136,21 +234,136 @@ func calcularResumen(cfdis []complemento.CFDI) resumen {
 	return r
 }
 
+// filterTabOptions regresa las opciones fijas de la pestaña más los códigos
+// encontrados en las facturas cargadas que no estén en la lista fija
+func filterTabOptions(tab int) []string {
+	options := append([]string{}, filterTabsContent[tab]...)
+
+	known := make(map[string]bool, len(options))
+	for _, id := range options {
+		known[id] = true
+	}
+
+	extra := make([]string, 0)
+	for _, c := range originalCFDIS {
+		for _, id := range filterTabsValues[tab](c) {
+			if id == "" || known[id] {
+				continue
+			}
+			known[id] = true
+			extra = append(extra, id)
+		}
+	}
+	sort.Strings(extra)
+
+	return append(options, extra...)
+}
+
+// Pestaña de cada clave del SAT que se puede filtrar, las claves de las
+// pestañas con prefijo se reconocen por él
+var filterOptionTabs = map[string]int{}
+
+// Prefijo de las opciones de cada pestaña, vacío en las claves del SAT
+var filterTabsPrefix = []string{"", "", "", "", tagFilterPrefix, conciliacionFilterPrefix, problemaFilterPrefix, estatusFilterPrefix}
+
+// registerFilterOptions da de alta las claves de las facturas cargadas que no
+// están en las listas fijas, se llama cada vez que se cargan o recargan
+func registerFilterOptions() {
+	for tab, options := range filterTabsContent {
+		for _, id := range options {
+			filterOptionTabs[id] = tab
+		}
+	}
+
+	for _, c := range originalCFDIS {
+		for tab, values := range filterTabsValues {
+			if filterTabsPrefix[tab] != "" {
+				continue
+			}
+			for _, id := range values(c) {
+				if id == "" {
+					continue
+				}
+				if _, ok := filterOptionTabs[id]; !ok {
+					filterOptionTabs[id] = tab
+				}
+				if _, ok := listFilters[id]; !ok {
+					listFilters[id] = cfdiFilterOption{ID: id, Text: filterTabsDescription[tab](id)}
+				}
+			}
+		}
+	}
+}
+
+// filterOptionTab regresa la pestaña de la opción o -1 si no es de ninguna
+func filterOptionTab(id string) int {
+	for tab, prefix := range filterTabsPrefix {
+		if prefix != "" && strings.HasPrefix(id, prefix) {
+			return tab
+		}
+	}
+	if tab, ok := filterOptionTabs[id]; ok {
+		return tab
+	}
+	return -1
+}
+
+// filterOption regresa la opción con su descripción, las etiquetas, estados y
+// problemas cambian mientras se usa la tabla y no se dan de alta
+func filterOption(tab int, id string) cfdiFilterOption {
+	if option, ok := listFilters[id]; ok {
+		return option
+	}
+	return cfdiFilterOption{ID: id, Text: filterTabsDescription[tab](id)}
+}
+
+// filterFacets calcula para cada opción de la pestaña el resumen de las
+// facturas que seleccionaría, tomando en cuenta los filtros de las otras pestañas
+func filterFacets(tab int) map[string]resumen {
+	base := FacetFilterChain(activeFilters, tab).Apply(originalCFDIS)
+	return groupResumen(base, filterTabsValues[tab])
+}
+
+// groupResumen calcula el resumen de las facturas agrupadas por los valores
+// que regresa values, una factura cuenta en cada uno de sus valores
+func groupResumen(cfdis []complemento.CFDI, values func(complemento.CFDI) []string) map[string]resumen {
+	grouped := make(map[string][]complemento.CFDI)
+	for _, c := range cfdis {
+		for _, id := range values(c) {
+			grouped[id] = append(grouped[id], c)
+		}
+	}
+
+	resumenes := make(map[string]resumen, len(grouped))
+	for id, cfdis := range grouped {
+		resumenes[id] = calcularResumen(cfdis)
+	}
+
+	return resumenes
+}
+
 // filterCFDIS es ahora un alias para GenericFilterCFDIS que se encuentra en filters.go
 // Mantenemos esta función para compatibilidad con el código existente
 func filterCFDIS(cfdis []complemento.CFDI) []complemento.CFDI {
 	return GenericFilterCFDIS(cfdis)
 }
 
-func transformCFDIToRow(cfdi []complemento.CFDI) []table.Row {
+func transformCFDIToRow(cfdi []complemento.CFDI, columns []string, marked map[string]bool) []table.Row {
 	rows := make([]table.Row, 0)
+	visible := visibleColumns(columns)
 
 	for _, c := range cfdi {
-		row := table.Row{
-			c.Emisor.Nombre,
-			c.Receptor.Nombre,
-			c.Complemento.TimbreFiscalDigital.FechaTimbrado,
-			ac.FormatMoney(c.Total),
+		row := make(table.Row, 0, len(visible)+1)
+
+		//La primera columna indica si la factura está marcada
+		if marked[cfdiKey(c)] {
+			row = append(row, "●")
+		} else {
+			row = append(row, " ")
+		}
+
+		for _, column := range visible {
+			row = append(row, column.Value(c))
 		}
 		rows = append(rows, row)
 	}
@@ -0,0 +1,86 @@
+package table
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openResultMsg avisa al modelo el resultado de abrir un archivo
+type openResultMsg struct {
+	path string
+	err  error
+}
+
+// openerCommand regresa el comando del sistema operativo para abrir un archivo
+// con su programa predeterminado
+func openerCommand(path string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "windows":
+		//El primer argumento de start es el título de la ventana
+		return exec.Command("cmd", "/c", "start", "", path)
+	case "darwin":
+		return exec.Command("open", path)
+	default:
+		return exec.Command("xdg-open", path)
+	}
+}
+
+// openFile abre el archivo sin bloquear la interfaz
+func openFile(path string) tea.Cmd {
+	return func() tea.Msg {
+		if path == "" {
+			return openResultMsg{err: fmt.Errorf("no se conoce el archivo de la factura")}
+		}
+
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return openResultMsg{path: path, err: err}
+		}
+
+		if _, err := os.Stat(abs); err != nil {
+			return openResultMsg{path: abs, err: err}
+		}
+
+		if err := openerCommand(abs).Run(); err != nil {
+			return openResultMsg{path: abs, err: fmt.Errorf("no se pudo abrir %s: %w", abs, err)}
+		}
+
+		return openResultMsg{path: abs}
+	}
+}
+
+// pdfPath busca un PDF con el mismo nombre que el XML en la misma carpeta
+func pdfPath(xmlPath string) (string, bool) {
+	if xmlPath == "" {
+		return "", false
+	}
+
+	base := strings.TrimSuffix(xmlPath, filepath.Ext(xmlPath))
+	for _, ext := range []string{".pdf", ".PDF"} {
+		if _, err := os.Stat(base + ext); err == nil {
+			return base + ext, true
+		}
+	}
+
+	return "", false
+}
+
+// PDF encontrado junto a cada XML, vacío si no tiene. El detalle se pinta en
+// cada tecla y no debe revisar el disco cada vez
+var pdfCache = map[string]string{}
+
+// cachedPDFPath es pdfPath revisando el disco solo la primera vez por archivo
+func cachedPDFPath(xmlPath string) (string, bool) {
+	if pdf, ok := pdfCache[xmlPath]; ok {
+		return pdf, pdf != ""
+	}
+	pdf, ok := pdfPath(xmlPath)
+	pdfCache[xmlPath] = pdf
+	return pdf, ok
+}
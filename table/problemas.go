@@ -0,0 +1,85 @@
+package table
+
+import (
+	"strings"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/dannywolfmx/cfdi-xls/reglas"
+)
+
+// Prefijo de los filtros de problemas para no chocar con las claves del SAT
+const problemaFilterPrefix = "problema:"
+
+// Reglas de negocio que se revisan en cada factura
+var reglasEngine = reglas.Default()
+
+// Problemas de cada archivo, los filtros y la tabla los piden en cada refresco
+var problemasCache = map[string][]reglas.Problem{}
+
+// SetReglas cambia las reglas que se revisan en las facturas
+func SetReglas(e *reglas.Engine) {
+	reglasEngine = e
+	problemasCache = map[string][]reglas.Problem{}
+}
+
+func cfdiProblemas(c complemento.CFDI) []reglas.Problem {
+	if problems, ok := problemasCache[c.Path]; ok {
+		return problems
+	}
+
+	problems := reglasEngine.Check(c)
+	//Sin archivo no hay con qué distinguir la factura
+	if c.Path != "" {
+		problemasCache[c.Path] = problems
+	}
+	return problems
+}
+
+// problemasText junta los mensajes de los problemas para la columna
+func problemasText(problems []reglas.Problem) string {
+	text := make([]string, 0, len(problems))
+	for _, p := range problems {
+		text = append(text, p.Mensaje)
+	}
+	return strings.Join(text, "; ")
+}
+
+// problemaFilterIDs regresa una opción por cada regla que no cumple la factura
+func problemaFilterIDs(c complemento.CFDI) []string {
+	ids := make([]string, 0)
+	seen := map[string]bool{}
+	for _, p := range cfdiProblemas(c) {
+		if !seen[p.Regla] {
+			seen[p.Regla] = true
+			ids = append(ids, problemaFilterPrefix+p.Regla)
+		}
+	}
+	return ids
+}
+
+// problemaText regresa la descripción de la regla de un filtro de problemas
+func problemaText(key string) string {
+	id := strings.TrimPrefix(key, problemaFilterPrefix)
+	if rule, ok := reglasEngine.Rule(id); ok {
+		return rule.Descripcion
+	}
+	return id
+}
+
+// problemasSection muestra los problemas de la factura en el detalle
+func problemasSection(c complemento.CFDI) string {
+	problems := cfdiProblemas(c)
+	if len(problems) == 0 {
+		return ""
+	}
+
+	lines := []string{labelStyle.Render("Problemas:")}
+	for _, p := range problems {
+		style := infoStyle
+		if p.Severity == reglas.Error {
+			style = warningStyle
+		}
+		lines = append(lines, style.Render(p.String()))
+	}
+	return strings.Join(lines, "\n")
+}
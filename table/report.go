@@ -0,0 +1,207 @@
+package table
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/dannywolfmx/cfdi-xls/sheet"
+)
+
+// Hojas del reporte de excel
+const (
+	reportSheetFacturas  = "Facturas"
+	reportSheetMensual   = "Resumen mensual"
+	reportSheetProveedor = "Por proveedor"
+	reportSheetFormaPago = "Por forma de pago"
+	reportSheetUsoCFDI   = "Por uso CFDI"
+)
+
+// Separa el mes y el tipo de comprobante en las claves del resumen mensual
+const reportKeySeparator = "|"
+
+// exportReport escribe un libro con el detalle de las facturas y sus totales
+// por mes, por proveedor, por forma de pago y por uso CFDI
+func exportReport(path string, cfdis []complemento.CFDI, columns []string) error {
+	pagos, err := cfdiPagos(cfdis)
+	if err != nil {
+		return err
+	}
+
+	file := sheet.NewFile(path)
+
+	file.RenameSheet(reportSheetFacturas)
+	writeDetailSheet(file, sheet.FormatXLSX, cfdis, columns)
+
+	file.AddSheet(reportSheetMensual)
+	writeMonthlySheet(file, cfdis, complemento.PagosPorMes(pagos))
+
+	file.AddSheet(reportSheetProveedor)
+	writeProviderSheet(file, cfdis)
+
+	file.AddSheet(reportSheetFormaPago)
+	writeTabSheet(file, cfdis, tabFormaPago)
+
+	file.AddSheet(reportSheetUsoCFDI)
+	writeTabSheet(file, cfdis, tabUsoCFDI)
+
+	file.AddSheet(reportSheetValidacion)
+	writeValidationSheet(file, cfdis)
+
+	if len(metadataSinXML) > 0 {
+		file.AddSheet(reportSheetSinXML)
+		writeSinXMLSheet(file, metadataSinXML)
+	}
+
+	if file.Err != nil {
+		return file.Err
+	}
+
+	return file.Save()
+}
+
+// writeMonthlySheet escribe los totales por mes y tipo de comprobante, los
+// pagos se suman en el mes en que se pagaron igual que en PrintPagos
+func writeMonthlySheet(file *sheet.SheetFile, cfdis []complemento.CFDI, pagos []complemento.PagosMes) {
+	resumenes := groupResumen(cfdis, func(c complemento.CFDI) []string {
+		return []string{cfdiMonth(c) + reportKeySeparator + c.TipoDeComprobante}
+	})
+
+	pagado := make(map[string]float64, len(pagos))
+	for _, mes := range pagos {
+		key := mes.Mes.Format("2006-01") + reportKeySeparator + filterTipoPago.ID
+		pagado[key] = mes.Total
+		if _, ok := resumenes[key]; !ok {
+			resumenes[key] = resumen{}
+		}
+	}
+
+	writeHeader(file, "Mes", "Tipo de comprobante", "Facturas", "SubTotal", "IVA", "Total", "Importe pagado")
+
+	for _, key := range sortedKeys(resumenes) {
+		mes, tipo, _ := strings.Cut(key, reportKeySeparator)
+
+		file.MoveRowDownAndResetColumn()
+		file.SetCellRight(mes)
+		file.SetCellRight(optionLabel(tabTipoComprobante, tipo))
+		writeResumenCells(file, resumenes[key])
+		file.SetMoneyRight(pagado[key])
+	}
+
+	file.SetTotalsRow("Total")
+}
+
+// writeProviderSheet escribe los totales por RFC del emisor, de mayor a menor total
+func writeProviderSheet(file *sheet.SheetFile, cfdis []complemento.CFDI) {
+	resumenes := groupResumen(cfdis, func(c complemento.CFDI) []string {
+		return []string{c.Emisor.RFC}
+	})
+
+	nombres := make(map[string]string, len(resumenes))
+	for _, c := range cfdis {
+		if nombres[c.Emisor.RFC] == "" {
+			nombres[c.Emisor.RFC] = c.Emisor.Nombre
+		}
+	}
+
+	rfcs := sortedKeys(resumenes)
+	sort.SliceStable(rfcs, func(i, j int) bool {
+		return resumenes[rfcs[i]].Total > resumenes[rfcs[j]].Total
+	})
+
+	writeHeader(file, "RFC", "Emisor", "Facturas", "SubTotal", "IVA", "Total")
+
+	for _, rfc := range rfcs {
+		file.MoveRowDownAndResetColumn()
+		file.SetCellRight(rfc)
+		file.SetCellRight(nombres[rfc])
+		writeResumenCells(file, resumenes[rfc])
+	}
+
+	file.SetTotalsRow("Total")
+}
+
+// writeTabSheet escribe los totales por cada clave de la pestaña de filtros
+func writeTabSheet(file *sheet.SheetFile, cfdis []complemento.CFDI, tab int) {
+	resumenes := groupResumen(cfdis, filterTabsValues[tab])
+
+	writeHeader(file, filterTabsTitles[tab], "Descripción", "Facturas", "SubTotal", "IVA", "Total")
+
+	for _, key := range sortedKeys(resumenes) {
+		file.MoveRowDownAndResetColumn()
+		file.SetCellRight(key)
+		file.SetCellRight(optionText(tab, key))
+		writeResumenCells(file, resumenes[key])
+	}
+
+	file.SetTotalsRow("Total")
+}
+
+func writeHeader(file *sheet.SheetFile, titles ...string) {
+	for _, title := range titles {
+		file.SetHeaderRight(title)
+	}
+}
+
+func writeResumenCells(file *sheet.SheetFile, r resumen) {
+	file.SetIntRight(r.CantidadFacturas)
+	file.SetMoneyRight(r.SubTotal)
+	file.SetMoneyRight(r.IVA)
+	file.SetMoneyRight(r.Total)
+}
+
+func sortedKeys(resumenes map[string]resumen) []string {
+	keys := make([]string, 0, len(resumenes))
+	for key := range resumenes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// cfdiMonth regresa el año y mes de emisión con formato 2006-01
+func cfdiMonth(c complemento.CFDI) string {
+	if len(c.Fecha) < len("2006-01") {
+		return c.Fecha
+	}
+	return c.Fecha[:len("2006-01")]
+}
+
+// optionText regresa la descripción de una clave de la pestaña de filtros
+func optionText(tab int, key string) string {
+	if key == "" {
+		return "Sin clave"
+	}
+	if option, ok := listFilters[key]; ok {
+		return option.Text
+	}
+	return filterTabsDescription[tab](key)
+}
+
+// optionLabel regresa la clave junto con su descripción, por ejemplo "I - Ingreso"
+func optionLabel(tab int, key string) string {
+	return key + " - " + optionText(tab, key)
+}
+
+// cfdiPagos toma los documentos pagados de los comprobantes de pago
+func cfdiPagos(cfdis []complemento.CFDI) ([]complemento.PrintablePagos, error) {
+	pagos := make([]complemento.PrintablePagos, 0)
+
+	for _, c := range cfdis {
+		if c.TipoDeComprobante != filterTipoPago.ID {
+			continue
+		}
+
+		//La fecha de timbrado solo es informativa, no afecta los totales
+		fechaTimbrado, _ := time.Parse(complemento.FechaLayout, c.Complemento.TimbreFiscalDigital.FechaTimbrado)
+
+		pagosCFDI, err := complemento.NewPrintablePagos(c.Emisor.Nombre, c.Receptor.Nombre, fechaTimbrado, c.Complemento.Pagos)
+		if err != nil {
+			return nil, err
+		}
+		pagos = append(pagos, pagosCFDI...)
+	}
+
+	return pagos, nil
+}
@@ -0,0 +1,149 @@
+package table
+
+import (
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// Debajo de este ancho se usa el modo compacto de una sola columna
+	compactWidth = 120
+
+	// Tamaño que se usa antes de recibir el primer tea.WindowSizeMsg
+	defaultWidth  = 190
+	defaultHeight = 40
+
+	// Borde de baseStyle, uno por cada lado
+	paneFrame = 2
+
+	minSidePanelWidth = 40
+	maxSidePanelWidth = 70
+	minTableHeight    = 3
+
+	// Altura de la fila de pestañas de los filtros
+	tabsHeight = 3
+
+	statusBarHeight = 1
+)
+
+// compact indica si la terminal es muy angosta para mostrar los paneles lado a lado
+func (m model) compact() bool {
+	return m.width < compactWidth
+}
+
+// paneWidths regresa el ancho exterior de la columna principal y del panel lateral
+func (m model) paneWidths() (int, int) {
+	if m.compact() {
+		return m.width, m.width
+	}
+
+	if m.hideFilters {
+		return m.width, 0
+	}
+
+	side := m.width * 3 / 10
+	if side < minSidePanelWidth {
+		side = minSidePanelWidth
+	}
+	if side > maxSidePanelWidth {
+		side = maxSidePanelWidth
+	}
+
+	return m.width - side, side
+}
+
+// applyLayout ajusta la tabla y las listas al tamaño actual de la terminal
+func (m *model) applyLayout() {
+	mainWidth, sideWidth := m.paneWidths()
+	mainInner := mainWidth - paneFrame
+	sideInner := sideWidth - paneFrame
+
+	//La tabla ocupa la altura que dejan libre el detalle, el resumen y la barra de estado
+	used := paneFrame + statusBarHeight
+	if m.showsDetail() {
+		used += lipgloss.Height(m.detailView(mainInner)) + paneFrame
+	}
+	if !m.hideResumen {
+		used += lipgloss.Height(resumenView(m.resumen, m.resumenMarcadas, m.nuevas, mainInner)) + paneFrame
+	}
+
+	tableHeight := m.height - used
+	if tableHeight < minTableHeight {
+		tableHeight = minTableHeight
+	}
+
+	m.table.SetColumns(fitColumns(tableColumns(m.columns, m.sortColumn, m.sortDesc), mainInner))
+	m.table.SetHeight(tableHeight)
+
+	//En modo compacto el panel lateral toma el lugar del detalle
+	listHeight := m.height - paneFrame - tabsHeight - statusBarHeight
+	if m.compact() {
+		listHeight = m.height - tableHeight - 2*paneFrame - tabsHeight - statusBarHeight
+		if !m.hideResumen {
+			listHeight -= lipgloss.Height(resumenView(m.resumen, m.resumenMarcadas, m.nuevas, mainInner)) + paneFrame
+		}
+	}
+	if listHeight < minTableHeight {
+		listHeight = minTableHeight
+	}
+
+	m.inspector.width = m.width
+	m.inspector.height = m.height - statusBarHeight
+	m.inspector.scrollToCursor()
+
+	m.filter.SetSize(sideInner, listHeight)
+	m.columnPicker.SetSize(sideInner, listHeight+tabsHeight)
+	m.actions.SetSize(sideInner, listHeight+tabsHeight)
+}
+
+// showsDetail indica si el detalle de la factura se muestra debajo de la tabla
+func (m model) showsDetail() bool {
+	if m.hideDetail {
+		return false
+	}
+	//En modo compacto el detalle se cambia por el panel lateral cuando tiene el foco
+	return !m.compact() || m.focusState == focusTable || m.hideFilters
+}
+
+// fitColumns escala el ancho de las columnas de forma proporcional para ocupar width
+func fitColumns(columns []table.Column, width int) []table.Column {
+	if len(columns) == 0 {
+		return columns
+	}
+
+	//Cada celda tiene un espacio de padding a cada lado
+	available := width - 2*len(columns)
+
+	total := 0
+	for _, c := range columns {
+		total += c.Width
+	}
+	if total == 0 || available <= 0 {
+		return columns
+	}
+
+	fitted := make([]table.Column, len(columns))
+	used := 0
+	for i, c := range columns {
+		//La columna de marcas conserva su ancho
+		if c.Width == 1 {
+			fitted[i] = c
+			used++
+			continue
+		}
+
+		w := c.Width * available / total
+		if w < 1 {
+			w = 1
+		}
+		fitted[i] = table.Column{Title: c.Title, Width: w}
+		used += w
+	}
+
+	//El sobrante por redondeo se agrega a la última columna
+	if rest := available - used; rest > 0 {
+		fitted[len(fitted)-1].Width += rest
+	}
+
+	return fitted
+}
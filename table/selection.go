@@ -0,0 +1,321 @@
+package table
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/muesli/termenv"
+)
+
+// cfdiKey identifica una factura cargada, cada archivo es una factura
+func cfdiKey(c complemento.CFDI) string {
+	if c.Path != "" {
+		return c.Path
+	}
+	return c.Complemento.TimbreFiscalDigital.UUID
+}
+
+// markedCFDIS regresa las facturas marcadas en el orden de carga, incluyendo
+// las que no se ven por los filtros
+func (m model) markedCFDIS() []complemento.CFDI {
+	marked := make([]complemento.CFDI, 0, len(m.marked))
+	for _, c := range originalCFDIS {
+		if m.marked[cfdiKey(c)] {
+			marked = append(marked, c)
+		}
+	}
+	return marked
+}
+
+// markVisible aplica fn a la marca de cada factura visible
+func (m *model) markVisible(fn func(marked bool) bool) {
+	for _, c := range m.cfdis {
+		key := cfdiKey(c)
+		if fn(m.marked[key]) {
+			m.marked[key] = true
+		} else {
+			delete(m.marked, key)
+		}
+	}
+	m.refreshMarks()
+}
+
+// refreshMarks actualiza la columna de marcas y el subtotal sin mover el cursor
+func (m *model) refreshMarks() {
+	m.table.SetRows(transformCFDIToRow(m.cfdis, m.columns, m.marked))
+	m.resumenMarcadas = calcularResumen(m.markedCFDIS())
+}
+
+// selectionAction es una acción que se puede aplicar a las facturas marcadas
+type selectionAction struct {
+	Text string
+	// Prompt es la pregunta para pedir una ruta, vacío si la acción no necesita ruta
+	Prompt string
+	// Suggestions autocompleta la captura, puede ser nil
+	Suggestions func() []string
+	Run         func(m *model, cfdis []complemento.CFDI, path string) (string, error)
+}
+
+var selectionActions = []selectionAction{
+	{Text: "Exportar a Excel (.xlsx)", Prompt: "Archivo xlsx: ", Run: func(m *model, cfdis []complemento.CFDI, path string) (string, error) {
+		return fmt.Sprintf("%d facturas exportadas a %s", len(cfdis), path), exportXLSX(path, cfdis, m.columns)
+	}},
+	{Text: "Exportar reporte de Excel (detalle y totales)", Prompt: "Archivo xlsx: ", Run: func(m *model, cfdis []complemento.CFDI, path string) (string, error) {
+		return fmt.Sprintf("Reporte de %d facturas exportado a %s", len(cfdis), path), exportReport(path, cfdis, m.columns)
+	}},
+	{Text: "Exportar a CSV, TSV, JSON o JSON Lines", Prompt: "Archivo (.csv, .tsv, .json, .jsonl): ", Run: func(m *model, cfdis []complemento.CFDI, path string) (string, error) {
+		return fmt.Sprintf("%d facturas exportadas a %s", len(cfdis), path), exportFile(path, cfdis, m.columns)
+	}},
+	{Text: "Generar PDF (representación impresa)", Prompt: "Carpeta destino: ", Run: func(m *model, cfdis []complemento.CFDI, path string) (string, error) {
+		n, err := writePDFs(cfdis, path)
+		return fmt.Sprintf("%d PDF generados en %s", n, path), err
+	}},
+	{Text: "Copiar UUIDs", Run: func(m *model, cfdis []complemento.CFDI, _ string) (string, error) {
+		return copyUUIDs(cfdis)
+	}},
+	{Text: "Copiar archivos a una carpeta", Prompt: "Carpeta destino: ", Run: func(m *model, cfdis []complemento.CFDI, path string) (string, error) {
+		n, err := m.transferFiles(cfdis, path, false)
+		return fmt.Sprintf("%d archivos copiados a %s", n, path), err
+	}},
+	{Text: "Mover archivos a una carpeta", Prompt: "Carpeta destino: ", Run: func(m *model, cfdis []complemento.CFDI, path string) (string, error) {
+		n, err := m.transferFiles(cfdis, path, true)
+		m.refreshTable()
+		return fmt.Sprintf("%d archivos movidos a %s", n, path), err
+	}},
+	{Text: "Etiquetar", Prompt: "Etiqueta: ", Suggestions: func() []string { return tagStore.AllTags() }, Run: func(m *model, cfdis []complemento.CFDI, tag string) (string, error) {
+		if tag == "" {
+			return "", fmt.Errorf("no se indicó la etiqueta")
+		}
+		//Las facturas sin timbrar no tienen UUID y no se pueden etiquetar
+		uuids := make([]string, 0, len(cfdis))
+		for _, c := range cfdis {
+			if uuid := c.Complemento.TimbreFiscalDigital.UUID; uuid != "" {
+				uuids = append(uuids, uuid)
+			}
+		}
+		err := tagStore.AddTag(uuids, tag)
+		m.refreshTable()
+		text := fmt.Sprintf("%d facturas etiquetadas como %q", len(uuids), tag)
+		if omitidas := len(cfdis) - len(uuids); omitidas > 0 {
+			text += fmt.Sprintf(", %d sin UUID omitidas", omitidas)
+		}
+		return text, err
+	}},
+}
+
+func actionListView() list.Model {
+	items := []list.Item{}
+	for _, a := range selectionActions {
+		items = append(items, item{text: a.Text})
+	}
+	return newOptionList(items, "Acciones")
+}
+
+func newPrompt(prompt string) textinput.Model {
+	input := textinput.New()
+	input.Prompt = prompt
+	input.Focus()
+	return input
+}
+
+// askText abre la captura de texto en la barra de estado, al confirmar se llama submit
+func (m *model) askText(prompt, value string, submit func(m *model, value string)) tea.Cmd {
+	m.prompt = newPrompt(prompt)
+	m.prompt.SetValue(value)
+	m.prompt.CursorEnd()
+	m.promptSubmit = submit
+	m.focusState = focusPrompt
+	return textinput.Blink
+}
+
+// updatePrompt maneja la captura de texto, esc cancela y enter confirma
+func (m model) updatePrompt(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.focusState = focusTable
+			m.promptSubmit = nil
+			m.applyLayout()
+			return m, nil
+		case "enter":
+			submit := m.promptSubmit
+			m.focusState = focusTable
+			m.promptSubmit = nil
+			submit(&m, strings.TrimSpace(m.prompt.Value()))
+			m.applyLayout()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.prompt, cmd = m.prompt.Update(msg)
+	return m, cmd
+}
+
+// runAction ejecuta la acción seleccionada sobre las facturas marcadas
+func (m *model) runAction(action selectionAction, path string) {
+	cfdis := m.markedCFDIS()
+	if len(cfdis) == 0 {
+		m.setStatus("No hay facturas marcadas", true)
+		return
+	}
+
+	text, err := action.Run(m, cfdis, path)
+	if err != nil {
+		m.setStatus(err.Error(), true)
+		return
+	}
+	m.setStatus(text, false)
+}
+
+// updateActions maneja el menú de acciones sobre las facturas marcadas
+func (m model) updateActions(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, isKey := msg.(tea.KeyMsg)
+	if isKey && key.String() == "ctrl+c" {
+		return m, tea.Quit
+	}
+
+	if isKey {
+		switch key.String() {
+		case "esc", "q", "e":
+			m.focusState = focusTable
+			m.applyLayout()
+			return m, nil
+		case "enter", " ":
+			action := selectionActions[m.actions.Index()]
+			if action.Prompt == "" {
+				m.focusState = focusTable
+				m.runAction(action, "")
+				m.applyLayout()
+				return m, nil
+			}
+			cmd := m.askText(action.Prompt, "", func(m *model, value string) {
+				m.runAction(action, value)
+			})
+			if action.Suggestions != nil {
+				m.prompt.ShowSuggestions = true
+				m.prompt.SetSuggestions(action.Suggestions())
+			}
+			return m, cmd
+		}
+	}
+
+	var cmd tea.Cmd
+	m.actions, cmd = m.actions.Update(msg)
+	return m, cmd
+}
+
+// copyUUIDs copia los UUIDs al portapapeles, si no hay portapapeles del sistema
+// usa la secuencia OSC52 de la terminal
+func copyUUIDs(cfdis []complemento.CFDI) (string, error) {
+	uuids := make([]string, 0, len(cfdis))
+	for _, c := range cfdis {
+		uuids = append(uuids, c.Complemento.TimbreFiscalDigital.UUID)
+	}
+	text := strings.Join(uuids, "\n")
+
+	if err := clipboard.WriteAll(text); err != nil {
+		termenv.Copy(text)
+		return fmt.Sprintf("%d UUIDs enviados al portapapeles de la terminal", len(uuids)), nil
+	}
+
+	return fmt.Sprintf("%d UUIDs copiados al portapapeles", len(uuids)), nil
+}
+
+// transferFiles copia o mueve el XML y su PDF (si existe) de cada factura a la carpeta
+func (m *model) transferFiles(cfdis []complemento.CFDI, dir string, move bool) (int, error) {
+	if dir == "" {
+		return 0, fmt.Errorf("no se indicó la carpeta destino")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, c := range cfdis {
+		files := []string{c.Path}
+		if pdf, ok := pdfPath(c.Path); ok {
+			files = append(files, pdf)
+		}
+
+		for _, src := range files {
+			dst := filepath.Join(dir, filepath.Base(src))
+			if err := transferFile(src, dst, move); err != nil {
+				return count, err
+			}
+			count++
+		}
+
+		if move {
+			m.updateCFDIPath(c.Path, filepath.Join(dir, filepath.Base(c.Path)))
+		}
+	}
+
+	return count, nil
+}
+
+func transferFile(src, dst string, move bool) error {
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("ya existe %s", dst)
+	}
+
+	if move {
+		//Rename falla entre discos distintos, en ese caso se copia y se borra
+		if err := os.Rename(src, dst); err == nil {
+			return nil
+		}
+	}
+
+	if err := copyFile(src, dst); err != nil {
+		return err
+	}
+
+	if move {
+		return os.Remove(src)
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// updateCFDIPath cambia la ruta de una factura movida para poder seguir abriéndola
+func (m *model) updateCFDIPath(oldPath, newPath string) {
+	for i := range originalCFDIS {
+		if originalCFDIS[i].Path == oldPath {
+			originalCFDIS[i].Path = newPath
+		}
+	}
+
+	if m.marked[oldPath] {
+		delete(m.marked, oldPath)
+		m.marked[newPath] = true
+	}
+}
@@ -0,0 +1,27 @@
+package table
+
+import (
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/dannywolfmx/cfdi-xls/sello"
+)
+
+// Revisa los sellos de las facturas, sin certificados del SAT solo se revisa el del emisor
+var verifier = &sello.Verifier{}
+
+// Resultado de revisar el sello de cada archivo, revisar vuelve a leer el XML
+var selloCache = map[string]sello.Result{}
+
+// SetVerifier cambia los certificados con que se revisan los sellos
+func SetVerifier(v *sello.Verifier) {
+	verifier = v
+	selloCache = map[string]sello.Result{}
+}
+
+func cfdiSello(c complemento.CFDI) sello.Result {
+	if r, ok := selloCache[c.Path]; ok {
+		return r
+	}
+	r := verifier.Verify(c)
+	selloCache[c.Path] = r
+	return r
+}
@@ -0,0 +1,143 @@
+package table
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/dannywolfmx/cfdi-xls/sheet"
+	"github.com/dannywolfmx/cfdi-xls/tags"
+)
+
+// Campos que se pueden usar en la ruta de exportación, por ejemplo
+// {receptor.rfc}/{yyyy}-{mm}/facturas.xlsx
+var templateFields = map[string]func(complemento.CFDI) string{
+	"emisor.rfc":      func(c complemento.CFDI) string { return c.Emisor.RFC },
+	"emisor.nombre":   func(c complemento.CFDI) string { return c.Emisor.Nombre },
+	"receptor.rfc":    func(c complemento.CFDI) string { return c.Receptor.RFC },
+	"receptor.nombre": func(c complemento.CFDI) string { return c.Receptor.Nombre },
+	"yyyy":            func(c complemento.CFDI) string { return datePart(c.Fecha, 0, 4) },
+	"mm":              func(c complemento.CFDI) string { return datePart(c.Fecha, 5, 7) },
+	"dd":              func(c complemento.CFDI) string { return datePart(c.Fecha, 8, 10) },
+	"tipo":            func(c complemento.CFDI) string { return c.TipoDeComprobante },
+	"metodo":          func(c complemento.CFDI) string { return c.MetodoPago },
+	"forma":           func(c complemento.CFDI) string { return c.FormaPago },
+	"uso":             func(c complemento.CFDI) string { return c.Receptor.UsoCFDI },
+	"moneda":          func(c complemento.CFDI) string { return c.Moneda },
+	"serie":           func(c complemento.CFDI) string { return c.Serie },
+	"folio":           func(c complemento.CFDI) string { return c.Folio },
+	"uuid":            func(c complemento.CFDI) string { return c.Complemento.TimbreFiscalDigital.UUID },
+}
+
+var templateFieldPattern = regexp.MustCompile(`\{([a-z.]+)\}`)
+
+// Valor que se usa cuando el campo viene vacío en la factura
+const templateEmptyValue = "SIN-VALOR"
+
+// ExportedFile es un archivo escrito al separar la exportación
+type ExportedFile struct {
+	Path     string
+	Facturas int
+	Total    float64
+}
+
+// IsTemplate indica si la ruta tiene campos para separar las facturas en varios archivos
+func IsTemplate(path string) bool {
+	return templateFieldPattern.MatchString(path)
+}
+
+// validateTemplate revisa que todos los campos de la ruta existan
+func validateTemplate(template string) error {
+	for _, match := range templateFieldPattern.FindAllStringSubmatch(template, -1) {
+		if _, ok := templateFields[match[1]]; !ok {
+			return fmt.Errorf("campo {%s} no existe, usa %s", match[1], templateFieldList())
+		}
+	}
+	return nil
+}
+
+func templateFieldList() string {
+	names := make([]string, 0, len(templateFields))
+	for name := range templateFields {
+		names = append(names, "{"+name+"}")
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// expandTemplate reemplaza los campos de la ruta con los datos de la factura
+func expandTemplate(template string, c complemento.CFDI) string {
+	return templateFieldPattern.ReplaceAllStringFunc(template, func(field string) string {
+		value := templateFields[strings.Trim(field, "{}")](c)
+		return cleanPathPart(value)
+	})
+}
+
+// cleanPathPart quita los caracteres que no pueden ir en el nombre de un archivo
+func cleanPathPart(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, value)
+
+	value = strings.Trim(value, " .")
+	if value == "" {
+		return templateEmptyValue
+	}
+	return value
+}
+
+func datePart(fecha string, from, to int) string {
+	if len(fecha) < to {
+		return ""
+	}
+	return fecha[from:to]
+}
+
+// ExportSplit separa las facturas según la ruta con campos y escribe un
+// archivo por cada ruta distinta, creando los directorios que hagan falta.
+// Regresa lo que se escribió en cada archivo ordenado por ruta
+func ExportSplit(template string, format sheet.Format, store *tags.Store, cfdis []complemento.CFDI) ([]ExportedFile, error) {
+	if err := validateTemplate(template); err != nil {
+		return nil, err
+	}
+
+	tagStore = store
+	columns := loadLayout().Columns
+
+	groups := make(map[string][]complemento.CFDI)
+	for _, c := range cfdis {
+		path := filepath.Clean(expandTemplate(template, c))
+		groups[path] = append(groups[path], c)
+	}
+
+	paths := make([]string, 0, len(groups))
+	for path := range groups {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	written := make([]ExportedFile, 0, len(paths))
+	for _, path := range paths {
+		if dir := filepath.Dir(path); dir != "." {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return written, err
+			}
+		}
+
+		if err := writeExport(path, format, groups[path], columns); err != nil {
+			return written, err
+		}
+
+		r := calcularResumen(groups[path])
+		written = append(written, ExportedFile{Path: path, Facturas: len(groups[path]), Total: r.Total})
+	}
+
+	return written, nil
+}
@@ -3,7 +3,9 @@ package table
 import (
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/dannywolfmx/cfdi-xls/loader"
 	"github.com/leekchan/accounting"
 )
 
@@ -17,29 +19,76 @@ type focusState uint
 const (
 	focusTable focusState = iota
 	focusFilter
+	focusColumns
+	focusInspector
+	focusActions
+	focusPrompt
+	focusVerificacion
 )
 
 type resumen struct {
 	SubTotal         float64
 	Descuento        float64
+	IVA              float64
 	Total            float64
 	CantidadFacturas int
+	// Canceladas son las facturas canceladas que no se sumaron
+	Canceladas int
 }
 
 type model struct {
 	focusState focusState
 	table      table.Model
 	filter     list.Model
-	textarea   string
 	cfdis      []complemento.CFDI
 	cur        int
 	resumen    resumen
 	Tabs       []string
 	activeTab  int
+
+	// Columnas visibles y orden de la tabla
+	columns      []string
+	sortColumn   string
+	sortDesc     bool
+	columnPicker list.Model
+
+	// Tamaño de la terminal y paneles ocultos
+	width       int
+	height      int
+	hideDetail  bool
+	hideResumen bool
+	hideFilters bool
+
+	// Facturas marcadas, por cfdiKey, y las acciones sobre ellas
+	marked          map[string]bool
+	resumenMarcadas resumen
+	actions         list.Model
+
+	// Captura de texto en la barra de estado y qué hacer con el valor
+	prompt       textinput.Model
+	promptSubmit func(m *model, value string)
+	// exportPath es el último archivo donde se exportó la vista
+	exportPath string
+
+	// Cambios de los directorios vigilados, nil si no se vigilan
+	updates <-chan loader.Update
+	nuevas  int
+
+	// Inspector del XML de la factura seleccionada
+	inspector inspector
+
+	// Código QR de verificación de la factura seleccionada
+	verificacion verificacion
+
+	// Mensaje de la barra de estado
+	status      string
+	statusError bool
 }
 
 type item struct {
 	text string
+	// disabled marca las opciones sin facturas que coincidan
+	disabled bool
 }
 
 func (i item) FilterValue() string {
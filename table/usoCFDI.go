@@ -0,0 +1,50 @@
+package table
+
+import "fmt"
+
+var usoCFDI = map[string]string{
+	"G01":  "Adquisición de mercancias",
+	"G02":  "Devoluciones, descuentos o bonificaciones",
+	"G03":  "Gastos en general",
+	"I01":  "Construcciones",
+	"I02":  "Mobiliario y equipo de oficina por inversiones",
+	"I03":  "Equipo de transporte",
+	"I04":  "Equipo de computo y accesorios",
+	"I05":  "Dados, troqueles, moldes, matrices y herramental",
+	"I06":  "Comunicaciones telefónicas",
+	"I07":  "Comunicaciones satelitales",
+	"I08":  "Otra maquinaria y equipo",
+	"D01":  "Honorarios médicos, dentales y gastos hospitalarios",
+	"D02":  "Gastos médicos por incapacidad o discapacidad",
+	"D03":  "Gastos funerales",
+	"D04":  "Donativos",
+	"D05":  "Intereses reales efectivamente pagados por créditos hipotecarios",
+	"D06":  "Aportaciones voluntarias al SAR",
+	"D07":  "Primas por seguros de gastos médicos",
+	"D08":  "Gastos de transportación escolar obligatoria",
+	"D09":  "Depósitos en cuentas para el ahorro, primas de pensiones",
+	"D10":  "Pagos por servicios educativos (colegiaturas)",
+	"S01":  "Sin efectos fiscales",
+	"CP01": "Pagos",
+	"CN01": "Nómina",
+	"P01":  "Por definir",
+}
+
+// UsoCFDI returns the uso CFDI
+func UsoCFDI(key string) string {
+	//check if the key exists
+	_, ok := usoCFDI[key]
+	if !ok {
+		return fmt.Sprintf(" (%s) No existe el uso CFDI", key)
+	}
+
+	return fmt.Sprintf(" (%s) %s", key, usoCFDI[key])
+}
+
+// catalogText regresa la descripción de la clave o un texto genérico si no existe
+func catalogText(catalog map[string]string, key string) string {
+	if text, ok := catalog[key]; ok {
+		return text
+	}
+	return "Sin descripción"
+}
@@ -0,0 +1,104 @@
+package table
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dannywolfmx/cfdi-xls/complemento"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// verificacionURL regresa la liga para verificar la factura en el SAT, vacía
+// si la factura no está timbrada
+func verificacionURL(c complemento.CFDI) string {
+	if c.Complemento.TimbreFiscalDigital.UUID == "" {
+		return ""
+	}
+	return c.VerificacionURL()
+}
+
+// verificacion es el código QR de la factura seleccionada, se dibuja con
+// medios bloques para que se pueda escanear desde la terminal
+type verificacion struct {
+	uuid string
+	url  string
+	qr   string
+}
+
+func newVerificacion(c complemento.CFDI) (verificacion, error) {
+	url := verificacionURL(c)
+	if url == "" {
+		return verificacion{}, fmt.Errorf("la factura no está timbrada, no tiene liga de verificación")
+	}
+
+	code, err := qrcode.New(url, qrcode.Low)
+	if err != nil {
+		return verificacion{}, err
+	}
+
+	return verificacion{
+		uuid: c.Complemento.TimbreFiscalDigital.UUID,
+		url:  url,
+		//Los módulos oscuros van en blanco para terminales con fondo oscuro
+		qr: strings.TrimSuffix(code.ToSmallString(false), "\n"),
+	}, nil
+}
+
+func (v verificacion) View(width, height int) string {
+	doc := strings.Builder{}
+	doc.WriteString(headerStyle.Render("Verificación SAT " + v.uuid))
+	doc.WriteString("\n\n")
+	doc.WriteString(v.qr)
+	doc.WriteString("\n\n")
+	doc.WriteString(lipgloss.NewStyle().Width(width).Render(v.url))
+	doc.WriteString("\n")
+	doc.WriteString(infoStyle.Render("enter: abrir en el navegador • esc: cerrar"))
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, doc.String())
+}
+
+func (m model) updateVerificacion(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.applyLayout()
+	case openResultMsg:
+		if msg.err != nil {
+			m.setStatus(msg.err.Error(), true)
+		} else {
+			m.setStatus("Abierto "+msg.path, false)
+		}
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc", "q", "V":
+			m.focusState = focusTable
+			m.applyLayout()
+		case "enter":
+			m.setStatus("Abriendo "+m.verificacion.url, false)
+			return m, openURL(m.verificacion.url)
+		}
+	}
+	return m, nil
+}
+
+// openURL abre la liga en el navegador sin bloquear la interfaz
+func openURL(url string) tea.Cmd {
+	return func() tea.Msg {
+		//start de cmd corta la liga en cada &, se abre con el manejador de protocolos
+		cmd := openerCommand(url)
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+		}
+
+		if err := cmd.Run(); err != nil {
+			return openResultMsg{path: url, err: fmt.Errorf("no se pudo abrir %s: %w", url, err)}
+		}
+		return openResultMsg{path: url}
+	}
+}
@@ -11,6 +11,8 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/dannywolfmx/cfdi-xls/complemento"
+	"github.com/dannywolfmx/cfdi-xls/estatus"
+	"github.com/dannywolfmx/cfdi-xls/letra"
 )
 
 var baseStyle = lipgloss.NewStyle().
@@ -37,8 +39,7 @@ var (
 	headerStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("39")).
 			Background(lipgloss.Color("236")).
-			Bold(true).
-			Width(120) // No usar MarginBottom para ahorrar espacio
+			Bold(true) // No usar MarginBottom para ahorrar espacio
 
 	moneyStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("42")).
@@ -52,6 +53,15 @@ var (
 			Foreground(lipgloss.Color("33")).
 			Bold(true)
 
+	statusBarStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("250")).
+			Background(lipgloss.Color("236")).
+			PaddingLeft(1)
+
+	statusErrorStyle = statusBarStyle.
+				Foreground(lipgloss.Color("202")).
+				Bold(true)
+
 	// Estilos para layout compacto
 	inlineValueStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("15")).
@@ -75,14 +85,25 @@ var (
 
 	filterInactiveStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("250"))
+
+	// Opciones que no seleccionarían ninguna factura
+	filterDisabledStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("240"))
 )
 
 // View resumen con layout compacto
-func resumenView(r resumen) string {
+func resumenView(r resumen, marcadas resumen, nuevas int, width int) string {
 	var doc strings.Builder
-
-	// Header/Título
-	doc.WriteString(headerStyle.Render("Resumen de Facturas"))
+	sectionStyle := compactSectionStyle.Width(width - paneFrame)
+
+	// Header/Título, con el aviso de facturas nuevas en modo vigilar
+	title := "Resumen de Facturas"
+	if nuevas == 1 {
+		title += "  ● 1 nueva factura"
+	} else if nuevas > 1 {
+		title += fmt.Sprintf("  ● %d nuevas facturas", nuevas)
+	}
+	doc.WriteString(headerStyle.Width(width).Render(title))
 	doc.WriteString("\n")
 
 	// Sección de información financiera compacta
@@ -97,6 +118,8 @@ func resumenView(r resumen) string {
 	} else {
 		finanzasSection.WriteString(labelStyle.Render("Descuento:") + valueStyle.Render(ac.FormatMoney(r.Descuento)))
 	}
+	finanzasSection.WriteString("  ")
+	finanzasSection.WriteString(labelStyle.Render("IVA:") + moneyStyle.Render(ac.FormatMoney(r.IVA)))
 	finanzasSection.WriteString("\n")
 
 	// Segunda línea: Total y cantidad de facturas
@@ -111,17 +134,33 @@ func resumenView(r resumen) string {
 		finanzasSection.WriteString(labelStyle.Render("Promedio:") + valueStyle.Render(ac.FormatMoney(promedio)))
 	}
 
-	doc.WriteString(compactSectionStyle.Render(finanzasSection.String()))
+	if r.Canceladas > 0 {
+		finanzasSection.WriteString("  ")
+		finanzasSection.WriteString(labelStyle.Render("Canceladas:") + warningStyle.Render(strconv.Itoa(r.Canceladas)+" sin sumar"))
+	}
+
+	// Tercera línea: subtotal de las facturas marcadas
+	if marcadas.CantidadFacturas > 0 {
+		finanzasSection.WriteString("\n")
+		finanzasSection.WriteString(labelStyle.Render("Marcadas:") + infoStyle.Render(strconv.Itoa(marcadas.CantidadFacturas)))
+		finanzasSection.WriteString("  ")
+		finanzasSection.WriteString(labelStyle.Render("SubTotal:") + moneyStyle.Render(ac.FormatMoney(marcadas.SubTotal)))
+		finanzasSection.WriteString("  ")
+		finanzasSection.WriteString(labelStyle.Render("Total:") + moneyStyle.Render(ac.FormatMoney(marcadas.Total)))
+	}
+
+	doc.WriteString(sectionStyle.Render(finanzasSection.String()))
 
 	return doc.String()
 }
 
 // View for the individual row con layout compacto
-func rowView(cfdi complemento.CFDI) string {
+func rowView(cfdi complemento.CFDI, width int) string {
 	var doc strings.Builder
+	sectionStyle := compactSectionStyle.Width(width - paneFrame)
 
 	// Header/Título
-	doc.WriteString(headerStyle.Render("Detalles de la Factura"))
+	doc.WriteString(headerStyle.Width(width).Render("Detalles de la Factura"))
 	doc.WriteString("\n")
 
 	// Sección de información general compacta
@@ -136,8 +175,26 @@ func rowView(cfdi complemento.CFDI) string {
 	// Segunda línea: Fechas
 	generalSection.WriteString(labelStyle.Render("Emisión:") + inlineValueStyle.Render(cfdi.Fecha))
 	generalSection.WriteString(labelStyle.Render("Timbrado:") + valueStyle.Render(cfdi.Complemento.TimbreFiscalDigital.FechaTimbrado))
+	switch e := cfdiEstatus(cfdi); e {
+	case estatus.Cancelado:
+		r, _ := estatusCache.Get(cfdi.Complemento.TimbreFiscalDigital.UUID)
+		generalSection.WriteString("  " + warningStyle.Render(strings.TrimSpace(e.String()+" "+r.FechaCancelacion)))
+	case estatus.Vigente:
+		generalSection.WriteString("  " + infoStyle.Render(e.String()))
+	}
+	generalSection.WriteString("\n")
 
-	doc.WriteString(compactSectionStyle.Render(generalSection.String()))
+	// Tercera línea: archivo de origen y si tiene PDF
+	generalSection.WriteString(labelStyle.Render("Archivo:") + inlineValueStyle.Render(cfdi.Path))
+	if _, ok := cachedPDFPath(cfdi.Path); ok {
+		generalSection.WriteString(infoStyle.Render("PDF disponible (p)"))
+	}
+	if url := verificacionURL(cfdi); url != "" {
+		generalSection.WriteString("\n")
+		generalSection.WriteString(labelStyle.Render("Verificar:") + valueStyle.Render(url))
+	}
+
+	doc.WriteString(sectionStyle.Render(generalSection.String()))
 	doc.WriteString("\n")
 
 	// Sección de emisor y receptor compacta
@@ -148,7 +205,7 @@ func rowView(cfdi complemento.CFDI) string {
 	partesSection.WriteString(labelStyle.Render("Receptor:") + inlineValueStyle.Render(cfdi.Receptor.Nombre) +
 		labelStyle.Render("RFC:") + valueStyle.Render(cfdi.Receptor.RFC))
 
-	doc.WriteString(compactSectionStyle.Render(partesSection.String()))
+	doc.WriteString(sectionStyle.Render(partesSection.String()))
 	doc.WriteString("\n")
 
 	// Sección de pago compacta
@@ -167,7 +224,7 @@ func rowView(cfdi complemento.CFDI) string {
 		pagoSection.WriteString(labelStyle.Render("Moneda:") + valueStyle.Render(cfdi.Moneda+" (TC: "+cfdi.TipoCambio+")"))
 	}
 
-	doc.WriteString(compactSectionStyle.Render(pagoSection.String()))
+	doc.WriteString(sectionStyle.Render(pagoSection.String()))
 	doc.WriteString("\n")
 
 	// Sección de importes compacta
@@ -181,8 +238,33 @@ func rowView(cfdi complemento.CFDI) string {
 	}
 
 	importesSection.WriteString(labelStyle.Render("Total:") + moneyStyle.Render(ac.FormatMoney(cfdi.Total)))
+	importesSection.WriteString("\n")
+	importesSection.WriteString(labelStyle.Render("Con letra:") + valueStyle.Render(letra.Importe(cfdi.Total, cfdi.Moneda)))
+
+	doc.WriteString(sectionStyle.Render(importesSection.String()))
+
+	// Sección de problemas de las reglas de negocio, solo si tiene
+	if section := problemasSection(cfdi); section != "" {
+		doc.WriteString("\n")
+		doc.WriteString(sectionStyle.Render(section))
+	}
 
-	doc.WriteString(compactSectionStyle.Render(importesSection.String()))
+	// Sección de errores de esquema, solo si tiene
+	if section := esquemaSection(cfdi); section != "" {
+		doc.WriteString("\n")
+		doc.WriteString(sectionStyle.Render(section))
+	}
+
+	// Sección de anotaciones del usuario, solo si tiene
+	annotation := tagStore.Get(cfdi.Complemento.TimbreFiscalDigital.UUID)
+	if len(annotation.Tags) > 0 || annotation.Note != "" {
+		notasSection := strings.Builder{}
+		notasSection.WriteString(labelStyle.Render("Etiquetas:") + inlineValueStyle.Render(strings.Join(annotation.Tags, ", ")))
+		notasSection.WriteString(labelStyle.Render("Nota:") + valueStyle.Render(annotation.Note))
+
+		doc.WriteString("\n")
+		doc.WriteString(sectionStyle.Render(notasSection.String()))
+	}
 
 	return doc.String()
 }
@@ -269,56 +351,88 @@ func (m model) ViewFilter(lists list.Model) string {
 	return doc.String()
 }
 
+// sidePanelView muestra los filtros o el selector de columnas si está abierto
+func (m model) sidePanelView() string {
+	if m.focusState == focusColumns {
+		return m.columnPicker.View()
+	}
+	if m.focusState == focusActions {
+		return m.actions.View()
+	}
+	return m.ViewFilter(m.filter)
+}
+
+// detailView muestra el detalle de la factura seleccionada
+func (m model) detailView(width int) string {
+	if m.cur >= len(m.cfdis) {
+		return ""
+	}
+	return rowView(m.cfdis[m.cur], width)
+}
+
+// paneStyle resalta el borde del panel que tiene el foco
+func paneStyle(focused bool) lipgloss.Style {
+	if focused {
+		return baseStyle.BorderForeground(lipgloss.Color("69"))
+	}
+	return baseStyle
+}
+
 // View
 func (m model) View() string {
-	var s string
-	if m.focusState == focusTable {
-		s += lipgloss.JoinHorizontal(
-			lipgloss.Top,
-			lipgloss.JoinVertical(
-				lipgloss.Top,
-				baseStyle.
-					BorderStyle(lipgloss.NormalBorder()).
-					BorderForeground(lipgloss.Color("69")).
-					Render(m.table.View()),
-				baseStyle.
-					Width(128).
-					MarginTop(0). // Sin margen superior para ahorrar espacio
-					Render(m.textarea),
-				baseStyle.Width(128).Render(resumenView(m.resumen)),
-			),
-			lipgloss.JoinVertical(
-				lipgloss.Top,
-				baseStyle.Render(
-					m.ViewFilter(m.filter),
-				),
-			),
-		)
-	} else {
-		s += lipgloss.JoinHorizontal(
-			lipgloss.Top,
-			lipgloss.JoinVertical(
-				lipgloss.Top,
-				baseStyle.
-					Render(m.table.View()),
-				baseStyle.
-					Width(128).
-					MarginTop(0). // Sin margen superior para ahorrar espacio
-					Render(m.textarea),
-				baseStyle.Width(128).Render(resumenView(m.resumen)),
-			),
-			lipgloss.JoinVertical(
-				lipgloss.Top,
-				baseStyle.
-					BorderStyle(lipgloss.NormalBorder()).
-					BorderForeground(lipgloss.Color("69")).
-					Render(
-						m.ViewFilter(m.filter),
-					),
-			),
-		)
-	}
-	return s
+	if m.focusState == focusInspector {
+		return lipgloss.JoinVertical(lipgloss.Left, m.inspector.View(), m.statusBarView())
+	}
+	if m.focusState == focusVerificacion {
+		return lipgloss.JoinVertical(lipgloss.Left, m.verificacion.View(m.width, m.height-statusBarHeight), m.statusBarView())
+	}
+
+	mainWidth, sideWidth := m.paneWidths()
+	mainInner := mainWidth - paneFrame
+
+	//Las pestañas pueden ser más anchas que el panel, se recortan para no romper el borde
+	side := paneStyle(m.focusState != focusTable).Render(
+		lipgloss.NewStyle().MaxWidth(sideWidth - paneFrame).Render(m.sidePanelView()),
+	)
+
+	panes := []string{
+		paneStyle(m.focusState == focusTable).Render(m.table.View()),
+	}
+
+	if m.showsDetail() {
+		panes = append(panes, baseStyle.Width(mainInner).Render(m.detailView(mainInner)))
+	} else if m.compact() && !m.hideFilters {
+		panes = append(panes, side)
+	}
+
+	if !m.hideResumen {
+		panes = append(panes, baseStyle.Width(mainInner).Render(resumenView(m.resumen, m.resumenMarcadas, m.nuevas, mainInner)))
+	}
+
+	main := lipgloss.JoinVertical(lipgloss.Top, panes...)
+	if !m.compact() && !m.hideFilters {
+		main = lipgloss.JoinHorizontal(lipgloss.Top, main, side)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, main, m.statusBarView())
+}
+
+// statusBarView muestra el último mensaje o la ayuda de teclas
+func (m model) statusBarView() string {
+	if m.focusState == focusPrompt {
+		return statusBarStyle.Width(m.width).MaxWidth(m.width).Render(m.prompt.View())
+	}
+
+	style := statusBarStyle
+	text := m.status
+	if m.statusError {
+		style = statusErrorStyle
+	}
+	if text == "" {
+		text = "enter: abrir XML • p: abrir PDF • P: generar PDF • V: QR de verificación • i: inspeccionar • x/a/v/X: marcar • e: acciones • E: exportar vista • t/n: etiquetas/nota • tab: filtros • s/S: ordenar • c: columnas • q: salir"
+	}
+
+	return style.Width(m.width).MaxWidth(m.width).Render(text)
 }
 
 type itemDelegate struct{}
@@ -333,6 +447,9 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 	}
 
 	fn := itemStyle.Render
+	if i.disabled {
+		fn = itemStyle.Inherit(filterDisabledStyle).Render
+	}
 	if index == m.Index() {
 		fn = func(s ...string) string {
 			return selectedItemStyle.Render("> " + strings.Join(s, " "))
@@ -345,20 +462,57 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 func filterListView(activeTab int) list.Model {
 	items := []list.Item{}
 
-	for _, key := range filterTabsContent[activeTab] {
-		f := listFilters[key]
-		if _, ok := activeFilters[key]; ok {
-			items = append(items, item{text: fmt.Sprintf("%s %s-%s", filterActiveStyle.Render("✓"), f.ID, f.Text)})
-		} else {
-			items = append(items, item{text: fmt.Sprintf("%s %s-%s", filterInactiveStyle.Render("□"), f.ID, f.Text)})
+	facets := filterFacets(activeTab)
+
+	for _, key := range filterTabOptions(activeTab) {
+		f := filterOption(activeTab, key)
+		facet := facets[key]
+		count := fmt.Sprintf("(%d · %s)", facet.CantidadFacturas, ac.FormatMoney(facet.Total))
+		if facet.Canceladas > 0 {
+			count = fmt.Sprintf("(%d · %s · %d canceladas)", facet.CantidadFacturas, ac.FormatMoney(facet.Total), facet.Canceladas)
+		}
+
+		_, active := activeFilters[key]
+		disabled := facet.CantidadFacturas+facet.Canceladas == 0
+
+		// Las opciones sin coincidencias se pintan completas en gris
+		check := "□"
+		if active {
+			check = "✓"
+		}
+		if !disabled && active {
+			check = filterActiveStyle.Render(check)
+		} else if !disabled {
+			check = filterInactiveStyle.Render(check)
 		}
+
+		label := f.ID + "-" + f.Text
+		if textOnlyTab(activeTab) {
+			label = f.Text
+		}
+
+		items = append(items, item{text: fmt.Sprintf("%s %s %s", check, label, count), disabled: disabled})
+	}
+
+	if activeTab == tabConciliacion {
+		items = append(items, conciliacionSinXMLItems()...)
 	}
+	if activeTab == tabEstatus {
+		if sinXML, ok := metadataSinXMLItem(); ok {
+			items = append(items, sinXML)
+		}
+	}
+
+	return newOptionList(items, "Filtros") // Título más corto
+}
 
-	const defaultListWidth = 40
+// newOptionList crea una lista con el estilo del panel de filtros
+func newOptionList(items []list.Item, title string) list.Model {
+	const defaultListWidth = 60
 	const defaultListHeight = 20 // Altura reducida para la lista de filtros
 
 	l := list.New(items, itemDelegate{}, defaultListWidth, defaultListHeight)
-	l.Title = filterTitleStyle.Render("Filtros") // Título más corto
+	l.Title = filterTitleStyle.Render(title)
 	l.SetShowStatusBar(false)
 	l.SetFilteringEnabled(false)
 	l.InfiniteScrolling = true
@@ -0,0 +1,104 @@
+package table
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dannywolfmx/cfdi-xls/loader"
+)
+
+// watchMsg trae los cambios de los directorios vigilados
+type watchMsg loader.Update
+
+// waitForUpdate espera el siguiente cambio de los directorios vigilados
+func waitForUpdate(updates <-chan loader.Update) tea.Cmd {
+	return func() tea.Msg {
+		update, ok := <-updates
+		if !ok {
+			return nil
+		}
+		return watchMsg(update)
+	}
+}
+
+// applyUpdate agrega, reemplaza o quita facturas de originalCFDIS y vuelve a
+// pintar la tabla, los filtros y el resumen sin perder la fila seleccionada
+func (m *model) applyUpdate(update loader.Update) {
+	byPath := make(map[string]int, len(originalCFDIS))
+	for i, c := range originalCFDIS {
+		byPath[c.Path] = i
+	}
+
+	nuevas, actualizadas := 0, 0
+	for _, c := range update.CFDIs {
+		forgetFile(c.Path)
+		metadataFound(c)
+		if i, ok := byPath[c.Path]; ok {
+			originalCFDIS[i] = c
+			actualizadas++
+			continue
+		}
+		byPath[c.Path] = len(originalCFDIS)
+		originalCFDIS = append(originalCFDIS, c)
+		nuevas++
+	}
+
+	removed := make(map[string]bool, len(update.Removed))
+	for _, path := range update.Removed {
+		forgetFile(path)
+		if _, ok := byPath[path]; ok {
+			removed[path] = true
+			delete(m.marked, path)
+		}
+	}
+	if len(removed) > 0 {
+		kept := originalCFDIS[:0]
+		for _, c := range originalCFDIS {
+			if !removed[c.Path] {
+				kept = append(kept, c)
+			}
+		}
+		originalCFDIS = kept
+	}
+
+	//La fecha está en formato ISO, el orden del texto es el orden cronológico
+	sort.SliceStable(originalCFDIS, func(i, j int) bool {
+		return originalCFDIS[i].Fecha < originalCFDIS[j].Fecha
+	})
+	registerFilterOptions()
+
+	m.nuevas += nuevas
+	m.refreshKeepingCursor()
+
+	selected := m.filter.Index()
+	m.filter = filterListView(m.activeTab)
+	m.filter.Select(selected)
+
+	parts := make([]string, 0, 4)
+	if nuevas > 0 {
+		parts = append(parts, fmt.Sprintf("%d nuevas", nuevas))
+	}
+	if actualizadas > 0 {
+		parts = append(parts, fmt.Sprintf("%d actualizadas", actualizadas))
+	}
+	if len(removed) > 0 {
+		parts = append(parts, fmt.Sprintf("%d borradas", len(removed)))
+	}
+	if len(update.Errors) > 0 {
+		m.setStatus(fmt.Sprintf("%s. Error: %v", strings.Join(append(parts, fmt.Sprintf("%d con error", len(update.Errors))), ", "), update.Errors[0]), true)
+		return
+	}
+	if len(parts) > 0 {
+		m.setStatus("Facturas: "+strings.Join(parts, ", "), false)
+	}
+}
+
+// forgetFile quita lo que se revisó del archivo que cambió o se borró
+func forgetFile(path string) {
+	delete(selloCache, path)
+	delete(esquemaCache, path)
+	delete(pdfCache, path)
+	delete(problemasCache, path)
+}
@@ -0,0 +1,165 @@
+package tags
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Annotation son las etiquetas y la nota que el usuario le puso a una factura
+type Annotation struct {
+	Tags []string `json:"tags,omitempty"`
+	Note string   `json:"note,omitempty"`
+}
+
+// ErrSinUUID es el error al anotar una factura sin timbrar, todas tendrían la
+// misma clave vacía y compartirían las etiquetas
+var ErrSinUUID = errors.New("la factura no tiene UUID, no se le pueden poner etiquetas ni nota")
+
+// Store guarda las anotaciones en un archivo JSON junto a los cfdis, por UUID
+type Store struct {
+	path  string
+	items map[string]Annotation
+}
+
+// Open lee el archivo de anotaciones, si no existe se crea al guardar la primera
+func Open(path string) (*Store, error) {
+	s := &Store{
+		path:  path,
+		items: map[string]Annotation{},
+	}
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, &s.items); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func normalizeUUID(uuid string) string {
+	return strings.ToUpper(strings.TrimSpace(uuid))
+}
+
+// Get regresa la anotación de la factura, un Store nil no tiene anotaciones
+func (s *Store) Get(uuid string) Annotation {
+	if s == nil || normalizeUUID(uuid) == "" {
+		return Annotation{}
+	}
+	return s.items[normalizeUUID(uuid)]
+}
+
+// SetTags reemplaza las etiquetas de la factura y guarda el archivo
+func (s *Store) SetTags(uuid string, tags []string) error {
+	a := s.Get(uuid)
+	a.Tags = cleanTags(tags)
+	return s.set(uuid, a)
+}
+
+// AddTag agrega una etiqueta a cada factura y guarda el archivo una sola vez,
+// las facturas sin UUID se omiten
+func (s *Store) AddTag(uuids []string, tag string) error {
+	for _, uuid := range uuids {
+		if normalizeUUID(uuid) == "" {
+			continue
+		}
+		a := s.Get(uuid)
+		a.Tags = cleanTags(append(a.Tags, tag))
+		s.put(uuid, a)
+	}
+	return s.save()
+}
+
+// SetNote reemplaza la nota de la factura y guarda el archivo
+func (s *Store) SetNote(uuid string, note string) error {
+	a := s.Get(uuid)
+	a.Note = strings.TrimSpace(note)
+	return s.set(uuid, a)
+}
+
+// AllTags regresa todas las etiquetas usadas, ordenadas
+func (s *Store) AllTags() []string {
+	if s == nil {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	all := make([]string, 0)
+	for _, a := range s.items {
+		for _, tag := range a.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				all = append(all, tag)
+			}
+		}
+	}
+	sort.Strings(all)
+
+	return all
+}
+
+func (s *Store) set(uuid string, a Annotation) error {
+	if normalizeUUID(uuid) == "" {
+		return ErrSinUUID
+	}
+	s.put(uuid, a)
+	return s.save()
+}
+
+func (s *Store) put(uuid string, a Annotation) {
+	key := normalizeUUID(uuid)
+	if len(a.Tags) == 0 && a.Note == "" {
+		delete(s.items, key)
+		return
+	}
+	s.items[key] = a
+}
+
+// save escribe primero un archivo temporal para no dejar el archivo a medias
+func (s *Store) save() error {
+	content, err := json.MarshalIndent(s.items, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
+
+// cleanTags quita espacios, vacíos y repetidos conservando el orden
+func cleanTags(tags []string) []string {
+	seen := map[string]bool{}
+	clean := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		clean = append(clean, tag)
+	}
+	return clean
+}
+
+// ParseTags separa las etiquetas escritas con comas
+func ParseTags(text string) []string {
+	return cleanTags(strings.Split(text, ","))
+}